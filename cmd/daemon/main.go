@@ -2,17 +2,97 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"github.com/impactj90/lofi-tracker/cmd/internal/afk"
+	"github.com/impactj90/lofi-tracker/cmd/internal/branchwatch"
+	"github.com/impactj90/lofi-tracker/cmd/internal/config"
+	"github.com/impactj90/lofi-tracker/cmd/internal/daemon"
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/ipc"
 	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
 )
 
 func main() {
+	if !run() {
+		os.Exit(1)
+	}
+}
+
+// run is the daemon's main loop. It reports failures itself and returns
+// false instead of exiting, so the deferred cleanup still flushes the log and
+// releases the PID file.
+func run() bool {
+	configPath := flag.String("config", "", "Config file (default ~/.config/lofi-tracker/config.toml)")
+	dbPath := flag.String("db", "", "Database file, overrides db_path from the config")
+	idleThreshold := flag.Duration("idle-threshold", 0, "Pause after this long without input, overrides idle.threshold")
+	pollInterval := flag.Duration("poll-interval", 0, "Check for input this often while AFK, overrides idle.poll_interval")
+	noNotify := flag.Bool("no-notify", false, "Disable desktop notifications")
+	pidFile := flag.String("pid-file", "", "PID file locked while running (default ~/.lofi-tracker/lofi-daemon.pid)")
+	logFile := flag.String("log-file", "", "Write output to this rotating log file instead of the terminal")
+	socketPath := flag.String("socket", "", "Control socket for the CLI (default ~/.lofi-tracker/lofi-daemon.sock)")
+	flag.Parse()
+
+	if *logFile != "" {
+		logWriter, err := daemon.OpenRotatingLog(*logFile, daemon.LogMaxSize, daemon.LogBackups)
+		if err != nil {
+			fmt.Printf("Error opening log file: %v\n", err)
+			return false
+		}
+		defer logWriter.Close()
+
+		restore, err := daemon.RedirectOutput(logWriter)
+		if err != nil {
+			fmt.Printf("Error redirecting output: %v\n", err)
+			return false
+		}
+		defer restore()
+	}
+
+	if *pidFile == "" {
+		path, err := daemon.PIDPath()
+		if err != nil {
+			fmt.Printf("Error resolving PID file: %v\n", err)
+			return false
+		}
+		*pidFile = path
+	}
+	pid, err := daemon.AcquirePIDFile(*pidFile)
+	if err != nil {
+		fmt.Printf("Error starting daemon: %v\n", err)
+		return false
+	}
+	defer pid.Release()
+
+	cfg, err := loadConfig(*configPath, "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return false
+	}
+	if *dbPath != "" {
+		cfg.DBPath = *dbPath
+	}
+	if *idleThreshold > 0 {
+		cfg.Idle.Threshold = config.Duration(*idleThreshold)
+	}
+	if *pollInterval > 0 {
+		cfg.Idle.PollInterval = config.Duration(*pollInterval)
+	}
+	if *noNotify {
+		cfg.Notifications.Enabled = false
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Printf("Invalid config: %v\n", err)
+		return false
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Handle shutdown signals
@@ -21,26 +101,126 @@ func main() {
 
 	go func() {
 		sig := <-sigChan
-		fmt.Printf("Received shutdown signal %s", sig.String())
+		fmt.Printf("Received shutdown signal %s\n", sig.String())
 		cancel()
 	}()
 
-	tr, _, err := tracker.Init()
+	dbFile, err := cfg.ResolvedDBPath()
 	if err != nil {
-		fmt.Printf("Error initializing Tracker: %v", err)
-		os.Exit(1)
+		fmt.Printf("Error resolving database path: %v\n", err)
+		return false
+	}
+	database, err := db.NewSQLiteDB(dbFile)
+	if err != nil {
+		fmt.Printf("Error opening database: %v\n", err)
+		return false
 	}
+	defer database.Close()
 
-	daemon := afk.Daemon{
+	// The daemon does not need to run inside a repository, sessions started
+	// through the socket are recorded against the caller's repository.
+	repo, err := git.GetCurrentRepository()
+	if err != nil {
+		fmt.Printf("Not running inside a git repository (%v), sessions are recorded against the repository of each client\n", err)
+		repo = git.Repository{}
+	}
+	tr, err := tracker.ForRepository(cfg, repo, database)
+	if err != nil {
+		fmt.Printf("Error initializing Tracker: %v\n", err)
+		return false
+	}
+
+	if *socketPath == "" {
+		path, err := daemon.SocketPath()
+		if err != nil {
+			fmt.Printf("Error resolving control socket: %v\n", err)
+			return false
+		}
+		*socketPath = path
+	}
+	listener, err := ipc.Listen(*socketPath)
+	if err != nil {
+		fmt.Printf("Error opening control socket: %v\n", err)
+		return false
+	}
+	defer os.Remove(*socketPath)
+
+	absDBFile, _ := filepath.Abs(dbFile)
+	server := &ipc.Server{
+		Tracker: tr,
+		ForRepo: func(repo git.Repository) (tracker.Tracker, error) {
+			repoCfg, err := loadConfig(*configPath, repo.Root)
+			if err != nil {
+				return nil, err
+			}
+			return tracker.ForRepository(repoCfg, repo, database)
+		},
+		Info: ipc.PingResult{PID: os.Getpid(), DBPath: absDBFile},
+	}
+	go func() {
+		if err := server.Serve(ctx, listener); err != nil {
+			fmt.Printf("Control socket stopped: %v\n", err)
+		}
+	}()
+
+	policy, err := branchwatch.ParsePolicy(cfg.Watch.Policy)
+	if err != nil {
+		fmt.Printf("Invalid config: watch.policy: %v\n", err)
+		return false
+	}
+	repositories, err := cfg.WatchedRepositories()
+	if err != nil {
+		fmt.Printf("Error resolving watched repositories: %v\n", err)
+		return false
+	}
+
+	events, unsubscribe := server.Subscribe()
+	defer unsubscribe()
+
+	afkDaemon := afk.Daemon{
 		Afk: &afk.AfkWatcher{
-			Tracker:       tr,
-			IdleThreshold: time.Minute * 15,
+			Tracker:       server.Local(),
+			IdleThreshold: time.Duration(cfg.Idle.Threshold),
+			PollInterval:  time.Duration(cfg.Idle.PollInterval),
+			Notifications: cfg.Notifications.Enabled,
 			IsAfkActive:   false,
 		},
+		Watchers: []afk.Watcher{
+			&branchwatch.Watcher{
+				Tracker:       server.Local(),
+				SwitchIn:      server.SwitchIn,
+				Policy:        policy,
+				Debounce:      time.Duration(cfg.Watch.Debounce),
+				PollInterval:  time.Duration(cfg.Watch.PollInterval),
+				Repositories:  repositories,
+				Notifications: cfg.Notifications.Enabled,
+				Events:        events,
+			},
+		},
+	}
+
+	if name, err := afk.ProbeIdle(); err != nil {
+		fmt.Printf("No idle time provider works, AFK detection waits for one: %v\n", err)
+	} else {
+		fmt.Printf("Idle time from %s\n", name)
 	}
 
-	daemon.Run(ctx)
+	afkDaemon.Run(ctx)
 
 	<-ctx.Done()
 	fmt.Println("lofi-tracker daemon exited")
+	return true
+}
+
+// loadConfig loads the config file at path, or the default one, layered with
+// the .lofi-tracker.toml of the repository at repoRoot if set.
+func loadConfig(path, repoRoot string) (*config.Config, error) {
+	if path == "" {
+		var err error
+		path, err = config.Path()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return config.LoadWithRepo(path, repoRoot)
 }
@@ -2,10 +2,13 @@ package afk
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/gen2brain/beeep"
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/timer"
 	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
 )
 
@@ -14,17 +17,24 @@ var _ Watcher = (*AfkWatcher)(nil)
 type AfkWatcher struct {
 	Tracker       tracker.Tracker
 	IdleThreshold time.Duration
+	// PollInterval is how often input is checked for while AFK
+	PollInterval  time.Duration
+	Notifications bool
 	IsAfkActive   bool
+	// Clock drives the checks; nil means the system clock
+	Clock timer.Clock
+	// IdleTime reports how long there was no input; nil means GetIdleTime
+	IdleTime func() (time.Duration, error)
 }
 
 func (a *AfkWatcher) Start(ctx context.Context) error {
-	ticker := time.NewTicker(a.IdleThreshold)
+	ticker := a.clock().NewTicker(a.IdleThreshold)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
-		case <-ticker.C:
+		case <-ticker.C():
 			if err := a.detectAfk(ctx); err != nil {
 				fmt.Printf("%v", err)
 				continue
@@ -39,45 +49,71 @@ func (a *AfkWatcher) detectAfk(ctx context.Context) error {
 		return fmt.Errorf("x Failed to get Status: %v\n", err)
 	}
 
-	idleTime, err := GetIdleTime()
+	idleTime, err := a.idleTime()
 	if err != nil {
 		return fmt.Errorf("Error getting idle time: %v", err)
 	}
 
 	if idleTime >= a.IdleThreshold && !sessionStatus.IsAfk && !a.IsAfkActive {
-		err = a.Tracker.Pause(true)
+		// The pause starts at the last input, not when it was noticed, so
+		// the idle time before the threshold is not counted as work
+		lastInput := a.clock().Now().Add(-idleTime)
+		err = a.Tracker.PauseAt(lastInput, db.PauseReasonAfk, fmt.Sprintf("idle for %s", idleTime.Round(time.Minute)))
+		if errors.Is(err, tracker.ErrAlreadyPaused) {
+			// Paused by hand before going away; that pause keeps running
+			return nil
+		}
 		if err != nil {
 			return fmt.Errorf("❌ Failed to pause tracking: %v\n", err)
 		}
 
-		beeep.Notify("Lofi Tracker", "You've been paused due to inactivity. Working Session is Paused", "")
+		a.notify(fmt.Sprintf("You've been paused due to inactivity since %s. Working Session is Paused", lastInput.Local().Format("15:04")))
 		a.IsAfkActive = true
-		return a.watchForResume(ctx)
+		return a.watchForResume(ctx, lastInput)
 	}
 	return nil
 }
 
-func (a *AfkWatcher) watchForResume(ctx context.Context) error {
-	resumeThreshold := time.Second * 2
-	ticker := time.NewTicker(resumeThreshold)
+// watchForResume waits for input to end the AFK pause that began at
+// lastInput.
+func (a *AfkWatcher) watchForResume(ctx context.Context, lastInput time.Time) error {
+	resumeThreshold := a.PollInterval
+	if resumeThreshold <= 0 {
+		resumeThreshold = time.Second * 2
+	}
+	ticker := a.clock().NewTicker(resumeThreshold)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
-		case <-ticker.C:
-			idleTime, err := GetIdleTime()
+		case <-ticker.C():
+			// The session may have been resumed, completed or paused for
+			// another reason from the CLI in the meantime; then this AFK
+			// pause is no longer ours to resume.
+			status, err := a.Tracker.Status()
+			if err != nil || !status.IsPaused || !status.IsAfk {
+				a.IsAfkActive = false
+				return nil
+			}
+
+			idleTime, err := a.idleTime()
 			if err != nil {
 				return fmt.Errorf("Error getting idle time: %v", err)
 			}
 
 			if idleTime < resumeThreshold {
-				err := a.Tracker.Resume()
+				err := a.Tracker.ResumeFromAfk()
+				if errors.Is(err, tracker.ErrPausedManually) || errors.Is(err, tracker.ErrNotPaused) || errors.Is(err, db.ErrNoActiveSession) {
+					a.IsAfkActive = false
+					return nil
+				}
 				if err != nil {
 					return fmt.Errorf("❌ Failed to resume session: %v\n", err)
 				}
 
-				beeep.Notify("Lofi Tracker", "Welcome Back! Tracking resumed", "")
+				idle := tracker.FormatDuration(a.clock().Now().Sub(lastInput))
+				a.notify(fmt.Sprintf("Welcome Back! Tracking resumed. You were idle %s, run 'lofi-tracker idle keep' if that was work", idle))
 				a.IsAfkActive = false
 
 				return nil
@@ -85,3 +121,24 @@ func (a *AfkWatcher) watchForResume(ctx context.Context) error {
 		}
 	}
 }
+
+func (a *AfkWatcher) clock() timer.Clock {
+	if a.Clock == nil {
+		return timer.Real()
+	}
+	return a.Clock
+}
+
+func (a *AfkWatcher) idleTime() (time.Duration, error) {
+	if a.IdleTime == nil {
+		return GetIdleTime()
+	}
+	return a.IdleTime()
+}
+
+func (a *AfkWatcher) notify(message string) {
+	if !a.Notifications {
+		return
+	}
+	beeep.Notify("Lofi Tracker", message, "")
+}
@@ -0,0 +1,137 @@
+package afk
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/timer"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+)
+
+var testNow = time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC)
+
+// fakeTracker reports every call of the watcher on changes, so tests can
+// wait for the watcher to act on a tick.
+type fakeTracker struct {
+	tracker.Tracker
+
+	mu       sync.Mutex
+	status   tracker.SessionStatus
+	pausedAt time.Time
+	changes  chan string
+}
+
+func newFakeTracker() *fakeTracker {
+	return &fakeTracker{changes: make(chan string, 8)}
+}
+
+func (f *fakeTracker) Status() (tracker.SessionStatus, error) {
+	f.mu.Lock()
+	status := f.status
+	f.mu.Unlock()
+	f.changes <- "status"
+	return status, nil
+}
+
+func (f *fakeTracker) PauseAt(at time.Time, reason db.PauseReason, note string) error {
+	f.mu.Lock()
+	f.pausedAt = at
+	f.status.IsPaused = true
+	f.status.IsAfk = reason.IsAfk()
+	f.mu.Unlock()
+	f.changes <- "pause"
+	return nil
+}
+
+func (f *fakeTracker) ResumeFromAfk() error {
+	f.mu.Lock()
+	f.status.IsPaused = false
+	f.status.IsAfk = false
+	f.mu.Unlock()
+	f.changes <- "resume"
+	return nil
+}
+
+// idleClock reports idle time as the time since the last input on clock.
+type idleClock struct {
+	*timer.Fake
+	mu        sync.Mutex
+	lastInput time.Time
+}
+
+func (c *idleClock) input() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastInput = c.Now()
+}
+
+func (c *idleClock) idleTime() (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Now().Sub(c.lastInput), nil
+}
+
+func TestAfkWatcher_ShouldPauseAfterThresholdAndResumeOnInput(t *testing.T) {
+	clock := &idleClock{Fake: timer.NewFake(testNow), lastInput: testNow}
+	tr := newFakeTracker()
+	watcher := &AfkWatcher{
+		Tracker:       tr,
+		IdleThreshold: 10 * time.Minute,
+		PollInterval:  2 * time.Second,
+		Clock:         clock,
+		IdleTime: func() (time.Duration, error) {
+			defer func() { tr.changes <- "idle" }()
+			return clock.idleTime()
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Start(ctx)
+	clock.WaitForTickers(1)
+
+	// Input just before the first check keeps the session running
+	clock.Advance(9 * time.Minute)
+	clock.input()
+	clock.Advance(time.Minute)
+	expectChanges(t, tr, "status", "idle")
+
+	clock.Advance(10 * time.Minute)
+	expectChanges(t, tr, "status", "idle", "pause")
+	if want := testNow.Add(9 * time.Minute); !tr.pausedAt.Equal(want) {
+		t.Errorf("expected the pause to start at the last input at %v, got %v", want, tr.pausedAt)
+	}
+
+	clock.WaitForTickers(2)
+	clock.Advance(2 * time.Second)
+	expectChanges(t, tr, "status", "idle")
+
+	clock.Advance(time.Second)
+	clock.input()
+	clock.Advance(time.Second)
+	expectChanges(t, tr, "status", "idle", "resume")
+
+	clock.WaitForTickers(1)
+	select {
+	case change := <-tr.changes:
+		t.Errorf("expected no further changes, got %s", change)
+	default:
+	}
+}
+
+func expectChanges(t *testing.T, tr *fakeTracker, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case change := <-tr.changes:
+			if change != w {
+				t.Fatalf("expected %s, got %s", w, change)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %s, got nothing", w)
+		}
+	}
+}
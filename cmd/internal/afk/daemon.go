@@ -6,11 +6,15 @@ import (
 
 type Daemon struct {
 	Afk *AfkWatcher
+	// Watchers run alongside the AFK watcher until the daemon stops
+	Watchers []Watcher
 }
 
 func (d *Daemon) Run(ctx context.Context) {
 	go d.Afk.Start(ctx)
+	for _, w := range d.Watchers {
+		go w.Start(ctx)
+	}
 
 	<-ctx.Done()
 }
-
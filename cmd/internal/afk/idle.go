@@ -1,13 +1,168 @@
 package afk
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
 
 type IdelTimeProvider interface {
 	GetIdleTime() (time.Duration, error)
 }
 
-var idleProvider IdelTimeProvider
+// ErrNoIdleProvider is returned when none of the idle time providers works.
+var ErrNoIdleProvider = errors.New("no idle time provider works here")
 
+// IdleSource is a provider in an IdleChain. Open fails when the provider
+// cannot work on this machine, e.g. because a service is missing.
+type IdleSource struct {
+	Name string
+	Open func() (IdelTimeProvider, error)
+}
+
+// IdleDiagnosis is what a source answered when probed.
+type IdleDiagnosis struct {
+	Name     string
+	IdleTime time.Duration
+	Err      error
+}
+
+// IdleChain asks the first of its sources that works. When that provider
+// fails, the chain falls back to the others in order, so a restarted desktop
+// or a missing tool costs one check at most.
+type IdleChain struct {
+	sources []IdleSource
+
+	mu       sync.Mutex
+	current  int
+	provider IdelTimeProvider
+}
+
+// NewIdleChain returns a chain over sources, most preferred first.
+func NewIdleChain(sources ...IdleSource) *IdleChain {
+	return &IdleChain{sources: sources, current: -1}
+}
+
+// GetIdleTime implements IdelTimeProvider.
+func (c *IdleChain) GetIdleTime() (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.provider == nil {
+		return c.probe(-1)
+	}
+
+	idleTime, err := c.provider.GetIdleTime()
+	if err == nil {
+		return idleTime, nil
+	}
+
+	failed := c.current
+	c.drop()
+	idleTime, probeErr := c.probe(failed)
+	if probeErr != nil {
+		return 0, fmt.Errorf("%s: %w\n%w", c.sources[failed].Name, err, probeErr)
+	}
+	return idleTime, nil
+}
+
+// Probe picks a provider unless one is in use and returns its name.
+func (c *IdleChain) Probe() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.provider == nil {
+		if _, err := c.probe(-1); err != nil {
+			return "", err
+		}
+	}
+	return c.sources[c.current].Name, nil
+}
+
+// Diagnose asks every source of the chain, whether in use or not.
+func (c *IdleChain) Diagnose() []IdleDiagnosis {
+	diagnoses := make([]IdleDiagnosis, 0, len(c.sources))
+	for _, source := range c.sources {
+		d := IdleDiagnosis{Name: source.Name}
+		var p IdelTimeProvider
+		if p, d.Err = source.Open(); d.Err == nil {
+			d.IdleTime, d.Err = p.GetIdleTime()
+			closeProvider(p)
+		}
+		diagnoses = append(diagnoses, d)
+	}
+	return diagnoses
+}
+
+// Close releases the provider in use.
+func (c *IdleChain) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.drop()
+	return nil
+}
+
+// probe uses the first source but skip that opens and answers.
+func (c *IdleChain) probe(skip int) (time.Duration, error) {
+	errs := []error{ErrNoIdleProvider}
+	for i, source := range c.sources {
+		if i == skip {
+			continue
+		}
+
+		p, err := source.Open()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name, err))
+			continue
+		}
+		idleTime, err := p.GetIdleTime()
+		if err != nil {
+			closeProvider(p)
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name, err))
+			continue
+		}
+
+		c.current, c.provider = i, p
+		return idleTime, nil
+	}
+	return 0, errors.Join(errs...)
+}
+
+func (c *IdleChain) drop() {
+	if c.provider != nil {
+		closeProvider(c.provider)
+	}
+	c.current, c.provider = -1, nil
+}
+
+func closeProvider(p IdelTimeProvider) {
+	if closer, ok := p.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+var defaultChain = NewIdleChain(IdleSources()...)
+
+// GetIdleTime asks the providers of this platform, see IdleSources.
 func GetIdleTime() (time.Duration, error) {
-	return idleProvider.GetIdleTime()
+	return defaultChain.GetIdleTime()
+}
+
+// ProbeIdle picks the provider GetIdleTime uses and returns its name.
+func ProbeIdle() (string, error) {
+	return defaultChain.Probe()
+}
+
+// DiagnoseIdle asks each provider of this platform.
+func DiagnoseIdle() []IdleDiagnosis {
+	return NewIdleChain(IdleSources()...).Diagnose()
+}
+
+// IdleSources returns the idle time providers of this platform, most
+// preferred first. The shell heartbeat comes last everywhere.
+func IdleSources() []IdleSource {
+	return append(platformIdleSources(), IdleSource{Name: "heartbeat", Open: openHeartbeatIdle})
 }
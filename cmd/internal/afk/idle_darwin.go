@@ -37,6 +37,8 @@ func (m *macIdle) GetIdleTime() (time.Duration, error) {
 	return time.Duration(nanoseconds), nil
 }
 
-func init() {
-	idleProvider = &macIdle{}
+func platformIdleSources() []IdleSource {
+	return []IdleSource{
+		{Name: "iokit", Open: func() (IdelTimeProvider, error) { return &macIdle{}, nil }},
+	}
 }
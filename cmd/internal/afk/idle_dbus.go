@@ -0,0 +1,161 @@
+//go:build linux
+
+package afk
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Wayland compositors don't let clients watch input, so on Wayland idle time
+// comes from the desktop over D-Bus instead.
+
+// busConn is the connection of a D-Bus provider.
+type busConn struct {
+	conn *dbus.Conn
+}
+
+func (b busConn) Close() error {
+	return b.conn.Close()
+}
+
+// mutterIdle asks GNOME Shell.
+type mutterIdle struct {
+	busConn
+}
+
+func (m *mutterIdle) GetIdleTime() (time.Duration, error) {
+	var idleMs uint64
+	err := m.conn.Object("org.gnome.Mutter.IdleMonitor", "/org/gnome/Mutter/IdleMonitor/Core").
+		Call("org.gnome.Mutter.IdleMonitor.GetIdletime", 0).
+		Store(&idleMs)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(idleMs) * time.Millisecond, nil
+}
+
+// screenSaverIdle asks the freedesktop screen saver service of KDE Plasma and
+// others. GNOME registers the service too but does not implement the call.
+type screenSaverIdle struct {
+	busConn
+}
+
+func (s *screenSaverIdle) GetIdleTime() (time.Duration, error) {
+	// The specification is vague about the unit; KDE, the implementation
+	// that matters, answers in milliseconds
+	var idleMs uint32
+	err := s.conn.Object("org.freedesktop.ScreenSaver", "/org/freedesktop/ScreenSaver").
+		Call("org.freedesktop.ScreenSaver.GetSessionIdleTime", 0).
+		Store(&idleMs)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(idleMs) * time.Millisecond, nil
+}
+
+// logindIdle reads the idle hint of the user's graphical session from
+// systemd-logind. The desktop only sets the hint after its own idle delay,
+// so until then the session counts as active.
+type logindIdle struct {
+	busConn
+	now func() time.Time
+}
+
+func (l *logindIdle) GetIdleTime() (time.Duration, error) {
+	session, err := l.session()
+	if err != nil {
+		return 0, err
+	}
+
+	idle, err := session.GetProperty("org.freedesktop.login1.Session.IdleHint")
+	if err != nil {
+		return 0, err
+	}
+	if isIdle, ok := idle.Value().(bool); !ok || !isIdle {
+		return 0, nil
+	}
+
+	since, err := session.GetProperty("org.freedesktop.login1.Session.IdleSinceHint")
+	if err != nil {
+		return 0, err
+	}
+	sinceUs, ok := since.Value().(uint64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected IdleSinceHint %v", since)
+	}
+
+	idleTime := l.now().Sub(time.UnixMicro(int64(sinceUs)))
+	if idleTime < 0 {
+		return 0, nil
+	}
+	return idleTime, nil
+}
+
+// session returns the graphical session of the user, which the daemon is
+// usually not part of itself.
+func (l *logindIdle) session() (dbus.BusObject, error) {
+	user := l.conn.Object("org.freedesktop.login1", "/org/freedesktop/login1/user/self")
+	display, err := user.GetProperty("org.freedesktop.login1.User.Display")
+	if err != nil {
+		return nil, err
+	}
+
+	// Display is the (id, path) of the session, with an empty id and the
+	// path "/" if the user has no graphical session
+	fields, ok := display.Value().([]interface{})
+	if !ok || len(fields) != 2 {
+		return nil, fmt.Errorf("unexpected Display %v", display)
+	}
+	path, ok := fields[1].(dbus.ObjectPath)
+	if !ok || path == "/" {
+		return nil, errors.New("no graphical session")
+	}
+
+	return l.conn.Object("org.freedesktop.login1", path), nil
+}
+
+func openMutterIdle() (IdelTimeProvider, error) {
+	conn, err := connectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+	return &mutterIdle{busConn{conn}}, nil
+}
+
+func openScreenSaverIdle() (IdelTimeProvider, error) {
+	conn, err := connectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+	return &screenSaverIdle{busConn{conn}}, nil
+}
+
+func openLogindIdle() (IdelTimeProvider, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, err
+	}
+	return &logindIdle{busConn: busConn{conn}, now: time.Now}, nil
+}
+
+// connectSessionBus connects to the running session bus. Unlike
+// dbus.ConnectSessionBus it never launches one.
+func connectSessionBus() (*dbus.Conn, error) {
+	conn, err := dbus.SessionBusPrivateNoAutoStartup()
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Auth(nil); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := conn.Hello(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
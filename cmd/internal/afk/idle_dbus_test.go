@@ -0,0 +1,276 @@
+//go:build linux
+
+package afk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// testBus stands in for a message bus. It speaks just enough of the protocol
+// for one client at a time and answers method calls and property reads from
+// its tables; everything else gets an UnknownMethod error.
+type testBus struct {
+	address string
+
+	mu         sync.Mutex
+	methods    map[string][]interface{}
+	properties map[string]interface{}
+}
+
+// newTestBus starts a bus on a socket in a temporary directory.
+func newTestBus(t *testing.T) *testBus {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "bus")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("expected no error listening, got %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	bus := &testBus{
+		address:    "unix:path=" + path,
+		methods:    map[string][]interface{}{},
+		properties: map[string]interface{}{},
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go bus.serve(conn)
+		}
+	}()
+	return bus
+}
+
+// Method answers calls of iface.member on path with body.
+func (b *testBus) Method(path dbus.ObjectPath, method string, body ...interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.methods[string(path)+" "+method] = body
+}
+
+// Property serves iface.name on path.
+func (b *testBus) Property(path dbus.ObjectPath, property string, value interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.properties[string(path)+" "+property] = value
+}
+
+func (b *testBus) serve(conn net.Conn) {
+	defer conn.Close()
+
+	in := bufio.NewReader(conn)
+	if err := b.authenticate(in, conn); err != nil {
+		return
+	}
+
+	for {
+		msg, err := dbus.DecodeMessage(in)
+		if err != nil {
+			return
+		}
+		if msg.Type != dbus.TypeMethodCall || msg.Flags&dbus.FlagNoReplyExpected != 0 {
+			continue
+		}
+
+		reply := b.answer(msg)
+		if err := reply.EncodeTo(conn, binary.LittleEndian); err != nil {
+			return
+		}
+	}
+}
+
+// authenticate accepts any EXTERNAL authentication.
+func (b *testBus) authenticate(in *bufio.Reader, conn net.Conn) error {
+	if _, err := in.ReadByte(); err != nil {
+		return err
+	}
+	for {
+		line, err := in.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		switch fields := bytes.Fields([]byte(line)); {
+		case len(fields) == 0:
+			return errors.New("empty auth line")
+		case string(fields[0]) == "AUTH" && len(fields) > 1 && string(fields[1]) == "EXTERNAL":
+			fmt.Fprint(conn, "OK 0123456789abcdef0123456789abcdef\r\n")
+		case string(fields[0]) == "AUTH":
+			fmt.Fprint(conn, "REJECTED EXTERNAL\r\n")
+		case string(fields[0]) == "BEGIN":
+			return nil
+		default:
+			fmt.Fprint(conn, "ERROR\r\n")
+		}
+	}
+}
+
+func (b *testBus) answer(call *dbus.Message) *dbus.Message {
+	path, _ := call.Headers[dbus.FieldPath].Value().(dbus.ObjectPath)
+	iface, _ := call.Headers[dbus.FieldInterface].Value().(string)
+	member, _ := call.Headers[dbus.FieldMember].Value().(string)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case iface == "org.freedesktop.DBus" && member == "Hello":
+		return reply(call, ":1.1")
+	case iface == "org.freedesktop.DBus.Properties" && member == "Get" && len(call.Body) == 2:
+		property := fmt.Sprintf("%s %s.%s", path, call.Body[0], call.Body[1])
+		if value, ok := b.properties[property]; ok {
+			return reply(call, dbus.MakeVariant(value))
+		}
+	default:
+		if body, ok := b.methods[fmt.Sprintf("%s %s.%s", path, iface, member)]; ok {
+			return reply(call, body...)
+		}
+	}
+
+	return &dbus.Message{
+		Type: dbus.TypeError,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldErrorName:   dbus.MakeVariant("org.freedesktop.DBus.Error.UnknownMethod"),
+			dbus.FieldReplySerial: dbus.MakeVariant(call.Serial()),
+			dbus.FieldSignature:   dbus.MakeVariant(dbus.SignatureOf("")),
+		},
+		Body: []interface{}{fmt.Sprintf("no %s.%s on %s", iface, member, path)},
+	}
+}
+
+func reply(call *dbus.Message, body ...interface{}) *dbus.Message {
+	msg := &dbus.Message{
+		Type: dbus.TypeMethodReply,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldReplySerial: dbus.MakeVariant(call.Serial()),
+		},
+		Body: body,
+	}
+	if len(body) > 0 {
+		msg.Headers[dbus.FieldSignature] = dbus.MakeVariant(dbus.SignatureOf(body...))
+	}
+	return msg
+}
+
+// display is the (so) struct of logind's User.Display
+type display struct {
+	ID   string
+	Path dbus.ObjectPath
+}
+
+// useTestBuses points the session and system bus at stand-ins.
+func useTestBuses(t *testing.T) (session, system *testBus) {
+	session, system = newTestBus(t), newTestBus(t)
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", session.address)
+	t.Setenv("DBUS_SYSTEM_BUS_ADDRESS", system.address)
+	return session, system
+}
+
+// newDBusChain returns a chain of the D-Bus providers only.
+func newDBusChain(t *testing.T) *IdleChain {
+	chain := NewIdleChain(
+		IdleSource{Name: "gnome", Open: openMutterIdle},
+		IdleSource{Name: "screensaver", Open: openScreenSaverIdle},
+		IdleSource{Name: "logind", Open: openLogindIdle},
+	)
+	t.Cleanup(func() { chain.Close() })
+	return chain
+}
+
+func TestDBusIdle_WhenMutterAnswers_ShouldUseIt(t *testing.T) {
+	session, _ := useTestBuses(t)
+	session.Method("/org/gnome/Mutter/IdleMonitor/Core", "org.gnome.Mutter.IdleMonitor.GetIdletime", uint64(90_000))
+	session.Method("/org/freedesktop/ScreenSaver", "org.freedesktop.ScreenSaver.GetSessionIdleTime", uint32(5_000))
+	chain := newDBusChain(t)
+
+	if name, err := chain.Probe(); err != nil || name != "gnome" {
+		t.Fatalf("expected gnome, got %q (%v)", name, err)
+	}
+	if idleTime, err := chain.GetIdleTime(); err != nil || idleTime != 90*time.Second {
+		t.Errorf("expected 1m30s idle, got %v (%v)", idleTime, err)
+	}
+}
+
+func TestDBusIdle_WhenOnlyScreenSaverAnswers_ShouldUseIt(t *testing.T) {
+	session, _ := useTestBuses(t)
+	session.Method("/org/freedesktop/ScreenSaver", "org.freedesktop.ScreenSaver.GetSessionIdleTime", uint32(42_000))
+	chain := newDBusChain(t)
+
+	if name, err := chain.Probe(); err != nil || name != "screensaver" {
+		t.Fatalf("expected screensaver, got %q (%v)", name, err)
+	}
+	if idleTime, err := chain.GetIdleTime(); err != nil || idleTime != 42*time.Second {
+		t.Errorf("expected 42s idle, got %v (%v)", idleTime, err)
+	}
+}
+
+func TestDBusIdle_WhenOnlyLogindAnswers_ShouldUseTheIdleHint(t *testing.T) {
+	_, system := useTestBuses(t)
+	sessionPath := dbus.ObjectPath("/org/freedesktop/login1/session/_32")
+	system.Property("/org/freedesktop/login1/user/self", "org.freedesktop.login1.User.Display", display{"2", sessionPath})
+	system.Property(sessionPath, "org.freedesktop.login1.Session.IdleHint", false)
+	system.Property(sessionPath, "org.freedesktop.login1.Session.IdleSinceHint", uint64(0))
+	chain := newDBusChain(t)
+
+	if name, err := chain.Probe(); err != nil || name != "logind" {
+		t.Fatalf("expected logind, got %q (%v)", name, err)
+	}
+	if idleTime, err := chain.GetIdleTime(); err != nil || idleTime != 0 {
+		t.Errorf("expected no idle time without the idle hint, got %v (%v)", idleTime, err)
+	}
+
+	chain.provider.(*logindIdle).now = func() time.Time { return testNow }
+	system.Property(sessionPath, "org.freedesktop.login1.Session.IdleHint", true)
+	system.Property(sessionPath, "org.freedesktop.login1.Session.IdleSinceHint", uint64(testNow.Add(-20*time.Minute).UnixMicro()))
+
+	if idleTime, err := chain.GetIdleTime(); err != nil || idleTime != 20*time.Minute {
+		t.Errorf("expected 20m idle, got %v (%v)", idleTime, err)
+	}
+}
+
+func TestDBusIdle_WhenNothingAnswers_ShouldReportEveryProvider(t *testing.T) {
+	_, system := useTestBuses(t)
+	system.Property("/org/freedesktop/login1/user/self", "org.freedesktop.login1.User.Display", display{"", "/"})
+
+	_, err := newDBusChain(t).Probe()
+	if !errors.Is(err, ErrNoIdleProvider) {
+		t.Fatalf("expected ErrNoIdleProvider, got %v", err)
+	}
+	for _, want := range []string{"gnome: no org.gnome.Mutter.IdleMonitor", "screensaver: no org.freedesktop.ScreenSaver", "logind: no graphical session"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected the error to mention %q, got %v", want, err)
+		}
+	}
+}
+
+func TestIdleSources_OnWayland_ShouldAskDBus(t *testing.T) {
+	session, _ := useTestBuses(t)
+	session.Method("/org/gnome/Mutter/IdleMonitor/Core", "org.gnome.Mutter.IdleMonitor.GetIdletime", uint64(3_000))
+	t.Setenv("XDG_SESSION_TYPE", "wayland")
+
+	chain := NewIdleChain(IdleSources()...)
+	defer chain.Close()
+
+	idleTime, err := chain.GetIdleTime()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if idleTime != 3*time.Second {
+		t.Errorf("expected 3s idle, got %v", idleTime)
+	}
+}
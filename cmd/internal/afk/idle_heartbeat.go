@@ -0,0 +1,45 @@
+package afk
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/daemon"
+)
+
+// heartbeatIdle takes the time since a shell hook last touched a file as the
+// idle time. It only notices typing in shells with the hook, which is better
+// than nothing where no other provider works.
+type heartbeatIdle struct {
+	path string
+	now  func() time.Time
+}
+
+func openHeartbeatIdle() (IdelTimeProvider, error) {
+	path, err := daemon.HeartbeatPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s does not exist, touch it from your shell prompt", path)
+	} else if err != nil {
+		return nil, err
+	}
+	return &heartbeatIdle{path: path, now: time.Now}, nil
+}
+
+func (h *heartbeatIdle) GetIdleTime() (time.Duration, error) {
+	info, err := os.Stat(h.path)
+	if err != nil {
+		return 0, err
+	}
+
+	idleTime := h.now().Sub(info.ModTime())
+	if idleTime < 0 {
+		return 0, nil
+	}
+	return idleTime, nil
+}
@@ -0,0 +1,97 @@
+//go:build linux
+
+package afk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// inputDevices are the evdev devices watched for activity.
+const inputDevices = "/dev/input/event*"
+
+// inputIdle reads the input devices itself and takes the time since the last
+// event as the idle time. This works under any display server, or none, but
+// needs read access to the devices, usually by membership in the input group.
+// Idle time counts from when the devices were opened.
+type inputIdle struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	lastInput time.Time
+	devices   []io.ReadCloser
+	open      int
+}
+
+func openInputIdle() (IdelTimeProvider, error) {
+	paths, err := filepath.Glob(inputDevices)
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []io.ReadCloser
+	var errs []error
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		devices = append(devices, f)
+	}
+	if len(devices) == 0 {
+		errs = append(errs, fmt.Errorf("no readable device in %s, is the user in the input group?", filepath.Dir(inputDevices)))
+		return nil, errors.Join(errs...)
+	}
+
+	return newInputIdle(devices, time.Now), nil
+}
+
+func newInputIdle(devices []io.ReadCloser, now func() time.Time) *inputIdle {
+	i := &inputIdle{now: now, lastInput: now(), devices: devices, open: len(devices)}
+	for _, device := range devices {
+		go i.watch(device)
+	}
+	return i
+}
+
+// watch notes every read from device as input. The events themselves don't
+// matter.
+func (i *inputIdle) watch(device io.Reader) {
+	buf := make([]byte, 4096)
+	for {
+		_, err := device.Read(buf)
+
+		i.mu.Lock()
+		if err != nil {
+			i.open--
+			i.mu.Unlock()
+			return
+		}
+		i.lastInput = i.now()
+		i.mu.Unlock()
+	}
+}
+
+func (i *inputIdle) GetIdleTime() (time.Duration, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.open == 0 {
+		return 0, errors.New("all input devices are gone")
+	}
+	return i.now().Sub(i.lastInput), nil
+}
+
+func (i *inputIdle) Close() error {
+	var errs []error
+	for _, device := range i.devices {
+		errs = append(errs, device.Close())
+	}
+	return errors.Join(errs...)
+}
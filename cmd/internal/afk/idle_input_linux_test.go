@@ -0,0 +1,81 @@
+//go:build linux
+
+package afk
+
+import (
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInputIdle_ShouldCountFromTheLastEvent(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	var mu sync.Mutex
+	now := testNow
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	idle := newInputIdle([]io.ReadCloser{r}, clock)
+	defer idle.Close()
+
+	advance(10 * time.Minute)
+	if idleTime, err := idle.GetIdleTime(); err != nil || idleTime != 10*time.Minute {
+		t.Fatalf("expected 10m idle since opening, got %v (%v)", idleTime, err)
+	}
+
+	if _, err := w.Write(make([]byte, 24)); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		idleTime, _ := idle.GetIdleTime()
+		return idleTime == 0
+	})
+
+	advance(time.Minute)
+	if idleTime, err := idle.GetIdleTime(); err != nil || idleTime != time.Minute {
+		t.Errorf("expected 1m idle since the event, got %v (%v)", idleTime, err)
+	}
+}
+
+func TestInputIdle_WhenDevicesAreGone_ShouldFail(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idle := newInputIdle([]io.ReadCloser{r}, func() time.Time { return testNow })
+	defer idle.Close()
+
+	w.Close()
+	waitFor(t, func() bool {
+		_, err := idle.GetIdleTime()
+		return err != nil
+	})
+}
+
+// waitFor polls cond until it holds, for events handled by other goroutines.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the condition to hold within a second, it did not")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
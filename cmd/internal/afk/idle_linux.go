@@ -4,32 +4,41 @@ package afk
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
-	"os"
 	"os/exec"
 	"strconv"
 	"strings"
 	"time"
 )
 
-type linuxIdle struct{}
+func platformIdleSources() []IdleSource {
+	return []IdleSource{
+		{Name: "gnome", Open: openMutterIdle},
+		{Name: "screensaver", Open: openScreenSaverIdle},
+		{Name: "x11", Open: openX11Idle},
+		{Name: "xprintidle", Open: openXprintidle},
+		{Name: "logind", Open: openLogindIdle},
+		{Name: "input", Open: openInputIdle},
+	}
+}
 
-func (l *linuxIdle) GetIdleTime() (time.Duration, error) {
-	// Optional: Check for unsupported Wayland
-	if os.Getenv("XDG_SESSION_TYPE") == "wayland" {
-		return 0, errors.New(`❌ Wayland is currently unsupported for AFK detection.
-Please switch to an X11 session or disable AFK tracking.`)
+// xprintidle runs the xprintidle tool of X11.
+type xprintidle struct{}
+
+func openXprintidle() (IdelTimeProvider, error) {
+	if _, err := x11DisplayEnv(); err != nil {
+		return nil, err
 	}
 
 	// Check if xprintidle is available
 	if _, err := exec.LookPath("xprintidle"); err != nil {
-		return 0, fmt.Errorf(`❌ "xprintidle" not found.
-
-To enable AFK detection on Linux (X11), install it with:
-  sudo apt install xprintidle`)
+		return nil, fmt.Errorf(`"xprintidle" not found, install it with: sudo apt install xprintidle`)
 	}
 
+	return &xprintidle{}, nil
+}
+
+func (x *xprintidle) GetIdleTime() (time.Duration, error) {
 	// Run the command
 	cmd := exec.Command("xprintidle")
 	var out bytes.Buffer
@@ -48,7 +57,3 @@ To enable AFK detection on Linux (X11), install it with:
 
 	return time.Duration(idleMs) * time.Millisecond, nil
 }
-
-func init() {
-	idleProvider = &linuxIdle{}
-}
@@ -0,0 +1,159 @@
+package afk
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// stubIdle answers with idleTime or err, and counts how often it was closed.
+type stubIdle struct {
+	idleTime time.Duration
+	err      error
+	closed   int
+}
+
+func (s *stubIdle) GetIdleTime() (time.Duration, error) {
+	return s.idleTime, s.err
+}
+
+func (s *stubIdle) Close() error {
+	s.closed++
+	return nil
+}
+
+func source(name string, p *stubIdle, openErr error) IdleSource {
+	return IdleSource{Name: name, Open: func() (IdelTimeProvider, error) {
+		if openErr != nil {
+			return nil, openErr
+		}
+		return p, nil
+	}}
+}
+
+func TestIdleChain_ShouldUseTheFirstProviderThatAnswers(t *testing.T) {
+	broken := &stubIdle{err: errors.New("no answer")}
+	working := &stubIdle{idleTime: time.Minute}
+	later := &stubIdle{idleTime: time.Hour}
+	chain := NewIdleChain(
+		source("missing", nil, errors.New("not installed")),
+		source("broken", broken, nil),
+		source("working", working, nil),
+		source("later", later, nil),
+	)
+
+	name, err := chain.Probe()
+	if err != nil || name != "working" {
+		t.Fatalf("expected working, got %q (%v)", name, err)
+	}
+	if broken.closed != 1 {
+		t.Errorf("expected the broken provider to be closed, got %d closes", broken.closed)
+	}
+	if idleTime, err := chain.GetIdleTime(); err != nil || idleTime != time.Minute {
+		t.Errorf("expected 1m idle, got %v (%v)", idleTime, err)
+	}
+}
+
+func TestIdleChain_WhenProviderFails_ShouldFallBack(t *testing.T) {
+	first := &stubIdle{idleTime: time.Minute}
+	second := &stubIdle{idleTime: time.Hour}
+	chain := NewIdleChain(source("first", first, nil), source("second", second, nil))
+
+	if _, err := chain.Probe(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	first.err = errors.New("desktop restarted")
+	idleTime, err := chain.GetIdleTime()
+	if err != nil || idleTime != time.Hour {
+		t.Fatalf("expected the second provider's 1h, got %v (%v)", idleTime, err)
+	}
+	if name, _ := chain.Probe(); name != "second" {
+		t.Errorf("expected second to be in use, got %q", name)
+	}
+	if first.closed != 1 {
+		t.Errorf("expected the failed provider to be closed, got %d closes", first.closed)
+	}
+
+	// Once the one in use fails as well, the first is tried again
+	first.err = nil
+	second.err = errors.New("gone")
+	if idleTime, err := chain.GetIdleTime(); err != nil || idleTime != time.Minute {
+		t.Errorf("expected the first provider's 1m, got %v (%v)", idleTime, err)
+	}
+}
+
+func TestIdleChain_WhenNothingWorks_ShouldReturnErrNoIdleProvider(t *testing.T) {
+	only := &stubIdle{idleTime: time.Minute}
+	chain := NewIdleChain(source("only", only, nil))
+	if _, err := chain.Probe(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	only.err = errors.New("gone")
+	if _, err := chain.GetIdleTime(); !errors.Is(err, ErrNoIdleProvider) {
+		t.Errorf("expected ErrNoIdleProvider, got %v", err)
+	}
+	if _, err := NewIdleChain().Probe(); !errors.Is(err, ErrNoIdleProvider) {
+		t.Errorf("expected ErrNoIdleProvider without sources, got %v", err)
+	}
+}
+
+func TestIdleChain_Diagnose_ShouldAskEverySource(t *testing.T) {
+	working := &stubIdle{idleTime: time.Minute}
+	chain := NewIdleChain(
+		source("missing", nil, errors.New("not installed")),
+		source("working", working, nil),
+		source("broken", &stubIdle{err: errors.New("no answer")}, nil),
+	)
+
+	diagnoses := chain.Diagnose()
+
+	if len(diagnoses) != 3 {
+		t.Fatalf("expected 3 diagnoses, got %d", len(diagnoses))
+	}
+	if diagnoses[0].Name != "missing" || diagnoses[0].Err == nil {
+		t.Errorf("expected missing to fail, got %+v", diagnoses[0])
+	}
+	if diagnoses[1].Err != nil || diagnoses[1].IdleTime != time.Minute {
+		t.Errorf("expected working to answer 1m, got %+v", diagnoses[1])
+	}
+	if diagnoses[2].Err == nil {
+		t.Errorf("expected broken to fail, got %+v", diagnoses[2])
+	}
+	if working.closed != 1 {
+		t.Errorf("expected probed providers to be closed, got %d closes", working.closed)
+	}
+}
+
+func TestHeartbeatIdle_ShouldCountFromTheLastTouch(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if _, err := openHeartbeatIdle(); err == nil {
+		t.Fatalf("expected an error without a heartbeat file, got none")
+	}
+
+	path := filepath.Join(home, ".lofi-tracker", "heartbeat")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, testNow, testNow.Add(-5*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := openHeartbeatIdle()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	p.(*heartbeatIdle).now = func() time.Time { return testNow }
+
+	if idleTime, err := p.GetIdleTime(); err != nil || idleTime != 5*time.Minute {
+		t.Errorf("expected 5m idle, got %v (%v)", idleTime, err)
+	}
+}
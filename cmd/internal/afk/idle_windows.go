@@ -33,6 +33,8 @@ func (w *winIdle) GetIdleTime() (time.Duration, error) {
 	return time.Duration(idleTicks) * time.Millisecond, nil
 }
 
-func init() {
-	idleProvider = &winIdle{}
+func platformIdleSources() []IdleSource {
+	return []IdleSource{
+		{Name: "win32", Open: func() (IdelTimeProvider, error) { return &winIdle{}, nil }},
+	}
 }
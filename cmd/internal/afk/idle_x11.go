@@ -0,0 +1,317 @@
+//go:build linux
+
+package afk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// x11Timeout bounds every exchange with the X server, so a hung server shows
+// up as an error and the chain moves on.
+const x11Timeout = 2 * time.Second
+
+// x11Idle asks the X server for the time since the last input through the
+// MIT-SCREEN-SAVER extension, the query xprintidle makes, over a connection
+// it keeps open instead of running a process every time.
+type x11Idle struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	root   uint32
+	opcode byte
+}
+
+func openX11Idle() (IdelTimeProvider, error) {
+	display, err := x11DisplayEnv()
+	if err != nil {
+		return nil, err
+	}
+	return dialX11Idle(display)
+}
+
+// x11DisplayEnv returns $DISPLAY where X11 idle time means something.
+func x11DisplayEnv() (string, error) {
+	// XWayland sets $DISPLAY too, but only sees input to X11 windows
+	if os.Getenv("XDG_SESSION_TYPE") == "wayland" {
+		return "", errors.New("not available on Wayland")
+	}
+	display := os.Getenv("DISPLAY")
+	if display == "" {
+		return "", errors.New("$DISPLAY is not set")
+	}
+	return display, nil
+}
+
+func dialX11Idle(display string) (*x11Idle, error) {
+	d, err := parseDisplay(display)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout(d.network, d.address, x11Timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(x11Timeout))
+
+	authName, authData := xauthCookie(d)
+	root, err := x11Setup(conn, authName, authData, d.screen)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	opcode, err := x11QueryExtension(conn, "MIT-SCREEN-SAVER")
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &x11Idle{conn: conn, root: root, opcode: opcode}, nil
+}
+
+func (x *x11Idle) GetIdleTime() (time.Duration, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.conn.SetDeadline(time.Now().Add(x11Timeout))
+
+	// ScreenSaverQueryInfo of the root window
+	req := []byte{x.opcode, 1}
+	req = binary.LittleEndian.AppendUint16(req, 2)
+	req = binary.LittleEndian.AppendUint32(req, x.root)
+	if _, err := x.conn.Write(req); err != nil {
+		return 0, err
+	}
+
+	reply, err := x11Reply(x.conn)
+	if err != nil {
+		return 0, err
+	}
+	idleMs := binary.LittleEndian.Uint32(reply[16:20])
+	return time.Duration(idleMs) * time.Millisecond, nil
+}
+
+func (x *x11Idle) Close() error {
+	return x.conn.Close()
+}
+
+// x11Display is where $DISPLAY points.
+type x11Display struct {
+	network string
+	address string
+	number  int
+	screen  int
+	// local is set for Unix sockets, which use the cookie of this host
+	local bool
+}
+
+// parseDisplay understands :0, :0.1, unix:0, host:10.0 and, as XQuartz sets
+// it, /path/to/socket:0.
+func parseDisplay(display string) (x11Display, error) {
+	i := strings.LastIndex(display, ":")
+	if i < 0 {
+		return x11Display{}, fmt.Errorf("invalid $DISPLAY %q", display)
+	}
+	host, rest := display[:i], display[i+1:]
+
+	number, screen, hasScreen := strings.Cut(rest, ".")
+	d := x11Display{}
+	var err error
+	if d.number, err = strconv.Atoi(number); err != nil {
+		return x11Display{}, fmt.Errorf("invalid $DISPLAY %q", display)
+	}
+	if hasScreen {
+		if d.screen, err = strconv.Atoi(screen); err != nil {
+			return x11Display{}, fmt.Errorf("invalid $DISPLAY %q", display)
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(host, "/"):
+		d.network, d.address, d.local = "unix", host, true
+	case host == "" || host == "unix":
+		d.network, d.address, d.local = "unix", filepath.Join("/tmp/.X11-unix", "X"+number), true
+	default:
+		d.network, d.address = "tcp", net.JoinHostPort(host, strconv.Itoa(6000+d.number))
+	}
+	return d, nil
+}
+
+// Xauthority families
+const (
+	xauthFamilyLocal = 256
+	xauthFamilyWild  = 65535
+)
+
+// xauthCookie returns the MIT-MAGIC-COOKIE-1 for d from $XAUTHORITY or
+// ~/.Xauthority. Without one the connection is tried unauthenticated, which
+// servers allowing the local user accept.
+func xauthCookie(d x11Display) (string, []byte) {
+	path := os.Getenv("XAUTHORITY")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil
+		}
+		path = filepath.Join(home, ".Xauthority")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil
+	}
+
+	hostname, _ := os.Hostname()
+	r := bytes.NewReader(data)
+	for {
+		var family uint16
+		if err := binary.Read(r, binary.BigEndian, &family); err != nil {
+			return "", nil
+		}
+		var fields [4][]byte
+		for i := range fields {
+			if fields[i], err = readXauthField(r); err != nil {
+				return "", nil
+			}
+		}
+		address, number, name, cookie := string(fields[0]), string(fields[1]), string(fields[2]), fields[3]
+
+		if name != "MIT-MAGIC-COOKIE-1" || (number != "" && number != strconv.Itoa(d.number)) {
+			continue
+		}
+		if !d.local || family == xauthFamilyWild || (family == xauthFamilyLocal && address == hostname) {
+			return name, cookie
+		}
+	}
+}
+
+func readXauthField(r io.Reader) ([]byte, error) {
+	var n uint16
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+	field := make([]byte, n)
+	_, err := io.ReadFull(r, field)
+	return field, err
+}
+
+// x11Setup opens the connection and returns the root window of screen.
+func x11Setup(conn net.Conn, authName string, authData []byte, screen int) (uint32, error) {
+	req := []byte{'l', 0}
+	req = binary.LittleEndian.AppendUint16(req, 11)
+	req = binary.LittleEndian.AppendUint16(req, 0)
+	req = binary.LittleEndian.AppendUint16(req, uint16(len(authName)))
+	req = binary.LittleEndian.AppendUint16(req, uint16(len(authData)))
+	req = append(req, 0, 0)
+	req = append(req, x11Pad([]byte(authName))...)
+	req = append(req, x11Pad(authData)...)
+	if _, err := conn.Write(req); err != nil {
+		return 0, err
+	}
+
+	head := make([]byte, 8)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return 0, err
+	}
+	body := make([]byte, int(binary.LittleEndian.Uint16(head[6:8]))*4)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return 0, err
+	}
+
+	switch head[0] {
+	case 0:
+		reason := body[:min(int(head[1]), len(body))]
+		return 0, fmt.Errorf("X server refused the connection: %s", reason)
+	case 2:
+		reason, _, _ := bytes.Cut(body, []byte{0})
+		return 0, fmt.Errorf("X server wants more authentication: %s", reason)
+	}
+
+	// The fixed part is followed by the vendor, the pixmap formats and the
+	// screens, each screen by its depths and their visuals
+	if len(body) < 32 {
+		return 0, errors.New("short X11 setup reply")
+	}
+	vendorLen := int(binary.LittleEndian.Uint16(body[16:18]))
+	screens, formats := int(body[20]), int(body[21])
+	if screen >= screens {
+		return 0, fmt.Errorf("X server has no screen %d", screen)
+	}
+
+	off := 32 + len(x11Pad(make([]byte, vendorLen))) + 8*formats
+	for i := 0; i < screen; i++ {
+		if len(body) < off+40 {
+			return 0, errors.New("short X11 setup reply")
+		}
+		depths := int(body[off+39])
+		off += 40
+		for j := 0; j < depths; j++ {
+			if len(body) < off+8 {
+				return 0, errors.New("short X11 setup reply")
+			}
+			off += 8 + 24*int(binary.LittleEndian.Uint16(body[off+2:off+4]))
+		}
+	}
+	if len(body) < off+4 {
+		return 0, errors.New("short X11 setup reply")
+	}
+	return binary.LittleEndian.Uint32(body[off : off+4]), nil
+}
+
+// x11QueryExtension returns the major opcode of the extension name.
+func x11QueryExtension(conn net.Conn, name string) (byte, error) {
+	padded := x11Pad([]byte(name))
+	req := []byte{98, 0}
+	req = binary.LittleEndian.AppendUint16(req, uint16(2+len(padded)/4))
+	req = binary.LittleEndian.AppendUint16(req, uint16(len(name)))
+	req = append(req, 0, 0)
+	req = append(req, padded...)
+	if _, err := conn.Write(req); err != nil {
+		return 0, err
+	}
+
+	reply, err := x11Reply(conn)
+	if err != nil {
+		return 0, err
+	}
+	if reply[8] == 0 {
+		return 0, fmt.Errorf("X server lacks the %s extension", name)
+	}
+	return reply[9], nil
+}
+
+// x11Reply reads the reply to the last request, skipping events.
+func x11Reply(conn net.Conn) ([]byte, error) {
+	for {
+		packet := make([]byte, 32)
+		if _, err := io.ReadFull(conn, packet); err != nil {
+			return nil, err
+		}
+
+		switch packet[0] {
+		case 0:
+			return nil, fmt.Errorf("X11 error %d", packet[1])
+		case 1:
+			extra := int(binary.LittleEndian.Uint32(packet[4:8])) * 4
+			if _, err := io.CopyN(io.Discard, conn, int64(extra)); err != nil {
+				return nil, err
+			}
+			return packet, nil
+		}
+	}
+}
+
+// x11Pad pads b with zeros to a multiple of four bytes.
+func x11Pad(b []byte) []byte {
+	return append(b, make([]byte, (4-len(b)%4)%4)...)
+}
@@ -0,0 +1,296 @@
+//go:build linux
+
+package afk
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testScreenSaverOpcode is the major opcode the fake X server gives the
+// MIT-SCREEN-SAVER extension.
+const testScreenSaverOpcode = 140
+
+// testX stands in for an X server with two screens. It speaks just enough of
+// the protocol to set up a connection, look up MIT-SCREEN-SAVER and answer
+// ScreenSaverQueryInfo; everything else gets a BadRequest error.
+type testX struct {
+	display string
+	// cookie is required from clients when set
+	cookie []byte
+	// screenSaver is whether the extension is there
+	screenSaver bool
+
+	mu        sync.Mutex
+	idleTime  time.Duration
+	drawables []uint32
+}
+
+// newTestX starts a server on a socket in a temporary directory.
+func newTestX(t *testing.T) *testX {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "X0")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("expected no error listening, got %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	x := &testX{display: path + ":0", screenSaver: true}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go x.serve(conn)
+		}
+	}()
+	return x
+}
+
+func (x *testX) setIdleTime(d time.Duration) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.idleTime = d
+}
+
+// drawable returns the window the last ScreenSaverQueryInfo asked about.
+func (x *testX) drawable() uint32 {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if len(x.drawables) == 0 {
+		return 0
+	}
+	return x.drawables[len(x.drawables)-1]
+}
+
+func (x *testX) serve(conn net.Conn) {
+	defer conn.Close()
+
+	if !x.setup(conn) {
+		return
+	}
+
+	var seq uint16
+	for {
+		head := make([]byte, 4)
+		if _, err := io.ReadFull(conn, head); err != nil {
+			return
+		}
+		req := make([]byte, int(binary.LittleEndian.Uint16(head[2:4]))*4-4)
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+		seq++
+
+		reply := make([]byte, 32)
+		reply[0] = 1
+		binary.LittleEndian.PutUint16(reply[2:4], seq)
+
+		switch {
+		case head[0] == 98:
+			name := string(req[4 : 4+binary.LittleEndian.Uint16(req[0:2])])
+			if name == "MIT-SCREEN-SAVER" && x.screenSaver {
+				reply[8], reply[9] = 1, testScreenSaverOpcode
+			}
+		case head[0] == testScreenSaverOpcode && head[1] == 1:
+			// An event first, which clients have to skip
+			event := make([]byte, 32)
+			event[0] = 12
+			conn.Write(event)
+
+			x.mu.Lock()
+			x.drawables = append(x.drawables, binary.LittleEndian.Uint32(req[0:4]))
+			binary.LittleEndian.PutUint32(reply[16:20], uint32(x.idleTime.Milliseconds()))
+			x.mu.Unlock()
+		default:
+			reply[0], reply[1] = 0, 1
+		}
+
+		if _, err := conn.Write(reply); err != nil {
+			return
+		}
+	}
+}
+
+// setup answers the connection setup, refusing a missing or wrong cookie.
+func (x *testX) setup(conn net.Conn) bool {
+	head := make([]byte, 12)
+	if _, err := io.ReadFull(conn, head); err != nil || head[0] != 'l' {
+		return false
+	}
+	nameLen := int(binary.LittleEndian.Uint16(head[6:8]))
+	dataLen := int(binary.LittleEndian.Uint16(head[8:10]))
+	auth := make([]byte, len(x11Pad(make([]byte, nameLen)))+len(x11Pad(make([]byte, dataLen))))
+	if _, err := io.ReadFull(conn, auth); err != nil {
+		return false
+	}
+	data := auth[len(auth)-len(x11Pad(make([]byte, dataLen))):][:dataLen]
+
+	if x.cookie != nil && string(data) != string(x.cookie) {
+		reason := x11Pad([]byte("No protocol specified"))
+		reply := []byte{0, 21, 11, 0, 0, 0}
+		reply = binary.LittleEndian.AppendUint16(reply, uint16(len(reason)/4))
+		conn.Write(append(reply, reason...))
+		return false
+	}
+
+	body := make([]byte, 32)
+	binary.LittleEndian.PutUint16(body[16:18], 4)
+	body[20], body[21] = 2, 1
+	body = append(body, "fake"...)
+	body = append(body, make([]byte, 8)...) // one pixmap format
+
+	// Screen 0 with one depth of one visual, screen 1 without any
+	screen := make([]byte, 40)
+	binary.LittleEndian.PutUint32(screen[0:4], 0x100)
+	screen[39] = 1
+	body = append(body, screen...)
+	depth := make([]byte, 8)
+	binary.LittleEndian.PutUint16(depth[2:4], 1)
+	body = append(body, depth...)
+	body = append(body, make([]byte, 24)...)
+	screen = make([]byte, 40)
+	binary.LittleEndian.PutUint32(screen[0:4], 0x200)
+	body = append(body, screen...)
+
+	reply := []byte{1, 0, 11, 0, 0, 0}
+	reply = binary.LittleEndian.AppendUint16(reply, uint16(len(body)/4))
+	_, err := conn.Write(append(reply, body...))
+	return err == nil
+}
+
+// writeXauthority writes a wildcard cookie for display 0 and points
+// $XAUTHORITY at it.
+func writeXauthority(t *testing.T, cookie []byte) {
+	t.Helper()
+
+	var entry []byte
+	entry = binary.BigEndian.AppendUint16(entry, xauthFamilyWild)
+	for _, field := range [][]byte{nil, []byte("0"), []byte("MIT-MAGIC-COOKIE-1"), cookie} {
+		entry = binary.BigEndian.AppendUint16(entry, uint16(len(field)))
+		entry = append(entry, field...)
+	}
+
+	path := filepath.Join(t.TempDir(), "Xauthority")
+	if err := os.WriteFile(path, entry, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XAUTHORITY", path)
+}
+
+func TestX11Idle_ShouldAskTheScreenSaverExtension(t *testing.T) {
+	x := newTestX(t)
+	x.cookie = []byte("0123456789abcdef")
+	writeXauthority(t, x.cookie)
+	x.setIdleTime(42 * time.Second)
+	t.Setenv("XDG_SESSION_TYPE", "x11")
+	t.Setenv("DISPLAY", x.display)
+
+	p, err := openX11Idle()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer p.(*x11Idle).Close()
+
+	if idleTime, err := p.GetIdleTime(); err != nil || idleTime != 42*time.Second {
+		t.Fatalf("expected 42s idle, got %v (%v)", idleTime, err)
+	}
+
+	// The connection stays open for the next tick
+	x.setIdleTime(1500 * time.Millisecond)
+	if idleTime, err := p.GetIdleTime(); err != nil || idleTime != 1500*time.Millisecond {
+		t.Errorf("expected 1.5s idle, got %v (%v)", idleTime, err)
+	}
+	if x.drawable() != 0x100 {
+		t.Errorf("expected the root window of screen 0, got %#x", x.drawable())
+	}
+}
+
+func TestX11Idle_WhenScreenIsGiven_ShouldAskItsRootWindow(t *testing.T) {
+	x := newTestX(t)
+	t.Setenv("XAUTHORITY", filepath.Join(t.TempDir(), "missing"))
+
+	p, err := dialX11Idle(x.display + ".1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer p.Close()
+
+	if _, err := p.GetIdleTime(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if x.drawable() != 0x200 {
+		t.Errorf("expected the root window of screen 1, got %#x", x.drawable())
+	}
+
+	if _, err := dialX11Idle(x.display + ".2"); err == nil {
+		t.Errorf("expected an error for a missing screen, got none")
+	}
+}
+
+func TestX11Idle_WhenCookieIsWrong_ShouldFail(t *testing.T) {
+	x := newTestX(t)
+	x.cookie = []byte("0123456789abcdef")
+	writeXauthority(t, []byte("fedcba9876543210"))
+
+	_, err := dialX11Idle(x.display)
+
+	if err == nil || !strings.Contains(err.Error(), "No protocol specified") {
+		t.Errorf("expected the server's refusal, got %v", err)
+	}
+}
+
+func TestX11Idle_WithoutExtension_ShouldFail(t *testing.T) {
+	x := newTestX(t)
+	x.screenSaver = false
+	t.Setenv("XAUTHORITY", filepath.Join(t.TempDir(), "missing"))
+
+	_, err := dialX11Idle(x.display)
+
+	if err == nil || !strings.Contains(err.Error(), "MIT-SCREEN-SAVER") {
+		t.Errorf("expected the missing extension to be named, got %v", err)
+	}
+}
+
+func TestX11Idle_OnWayland_ShouldNotConnect(t *testing.T) {
+	t.Setenv("XDG_SESSION_TYPE", "wayland")
+	t.Setenv("DISPLAY", ":0")
+
+	if _, err := openX11Idle(); err == nil {
+		t.Errorf("expected an error on Wayland, got none")
+	}
+}
+
+func TestParseDisplay(t *testing.T) {
+	tests := []struct {
+		display string
+		want    x11Display
+	}{
+		{":0", x11Display{network: "unix", address: "/tmp/.X11-unix/X0", local: true}},
+		{"unix:1.2", x11Display{network: "unix", address: "/tmp/.X11-unix/X1", number: 1, screen: 2, local: true}},
+		{"localhost:10.0", x11Display{network: "tcp", address: "localhost:6010", number: 10}},
+		{"/tmp/launch/org.xquartz:0", x11Display{network: "unix", address: "/tmp/launch/org.xquartz", local: true}},
+	}
+	for _, tt := range tests {
+		got, err := parseDisplay(tt.display)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: expected %+v, got %+v (%v)", tt.display, tt.want, got, err)
+		}
+	}
+
+	for _, display := range []string{"", "host", ":x", ":0.x"} {
+		if _, err := parseDisplay(display); err == nil {
+			t.Errorf("%q: expected an error, got none", display)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package branchwatch
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// notifier reports git directories whose HEAD file changed. Git replaces HEAD
+// by renaming HEAD.lock over it, so implementations watch the directory
+// rather than the file.
+type notifier interface {
+	Add(gitDir string) error
+	Remove(gitDir string)
+	// Events yields the git directory of every changed HEAD. Changes may be
+	// reported more than once.
+	Events() <-chan string
+	Close() error
+}
+
+// newNotifier returns the platform's file notifier, or a poller checking every
+// interval where there is none or it cannot be set up.
+func newNotifier(interval time.Duration) notifier {
+	if n, err := newPlatformNotifier(); err == nil {
+		return n
+	}
+	return newPoller(interval)
+}
+
+// poller compares the content of every watched HEAD file on each tick.
+type poller struct {
+	mu     sync.Mutex
+	heads  map[string][]byte
+	events chan string
+	done   chan struct{}
+}
+
+func newPoller(interval time.Duration) *poller {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	p := &poller{
+		heads:  map[string][]byte{},
+		events: make(chan string),
+		done:   make(chan struct{}),
+	}
+	go p.run(interval)
+	return p
+}
+
+func (p *poller) Add(gitDir string) error {
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.heads[gitDir] = head
+	return nil
+}
+
+func (p *poller) Remove(gitDir string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.heads, gitDir)
+}
+
+func (p *poller) Events() <-chan string {
+	return p.events
+}
+
+func (p *poller) Close() error {
+	close(p.done)
+	return nil
+}
+
+func (p *poller) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+
+		for _, gitDir := range p.changed() {
+			select {
+			case p.events <- gitDir:
+			case <-p.done:
+				return
+			}
+		}
+	}
+}
+
+// changed returns the git directories whose HEAD differs from the last look.
+func (p *poller) changed() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var changed []string
+	for gitDir, last := range p.heads {
+		head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+		if err != nil || bytes.Equal(head, last) {
+			continue
+		}
+		p.heads[gitDir] = head
+		changed = append(changed, gitDir)
+	}
+	return changed
+}
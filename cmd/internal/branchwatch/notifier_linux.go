@@ -0,0 +1,118 @@
+//go:build linux
+
+package branchwatch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// inotifyNotifier watches git directories with inotify.
+type inotifyNotifier struct {
+	fd   int
+	file *os.File
+
+	mu   sync.Mutex
+	wds  map[int32]string
+	dirs map[string]int32
+
+	events chan string
+	done   chan struct{}
+}
+
+func newPlatformNotifier() (notifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	// A non-blocking descriptor is handed to the runtime poller, so Close
+	// interrupts a pending Read.
+	n := &inotifyNotifier{
+		fd:     fd,
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		wds:    map[int32]string{},
+		dirs:   map[string]int32{},
+		events: make(chan string),
+		done:   make(chan struct{}),
+	}
+	go n.read()
+	return n, nil
+}
+
+func (n *inotifyNotifier) Add(gitDir string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, ok := n.dirs[gitDir]; ok {
+		return nil
+	}
+
+	wd, err := syscall.InotifyAddWatch(n.fd, gitDir, syscall.IN_CREATE|syscall.IN_MOVED_TO|syscall.IN_CLOSE_WRITE)
+	if err != nil {
+		return err
+	}
+	n.wds[int32(wd)] = gitDir
+	n.dirs[gitDir] = int32(wd)
+	return nil
+}
+
+func (n *inotifyNotifier) Remove(gitDir string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	wd, ok := n.dirs[gitDir]
+	if !ok {
+		return
+	}
+	syscall.InotifyRmWatch(n.fd, uint32(wd))
+	delete(n.wds, wd)
+	delete(n.dirs, gitDir)
+}
+
+func (n *inotifyNotifier) Events() <-chan string {
+	return n.events
+}
+
+func (n *inotifyNotifier) Close() error {
+	close(n.done)
+	return n.file.Close()
+}
+
+func (n *inotifyNotifier) read() {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		count, err := n.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= count; {
+			wd := int32(binary.NativeEndian.Uint32(buf[offset:]))
+			nameLen := int(binary.NativeEndian.Uint32(buf[offset+12:]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			name := string(bytes.TrimRight(buf[nameStart:nameStart+nameLen], "\x00"))
+			offset = nameStart + nameLen
+
+			if name != "HEAD" {
+				continue
+			}
+
+			n.mu.Lock()
+			gitDir, ok := n.wds[wd]
+			n.mu.Unlock()
+			if !ok {
+				continue
+			}
+
+			select {
+			case n.events <- gitDir:
+			case <-n.done:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package branchwatch
+
+import "errors"
+
+func newPlatformNotifier() (notifier, error) {
+	return nil, errors.New("no file notifications on this platform, polling instead")
+}
@@ -0,0 +1,26 @@
+package branchwatch
+
+import "fmt"
+
+// Policy is what the daemon does when a repository with an active session
+// checks out another branch.
+type Policy string
+
+const (
+	// PolicyAutoSwitch completes the session and starts one on the new branch
+	PolicyAutoSwitch Policy = "auto-switch"
+	// PolicyNotify only sends a notification
+	PolicyNotify Policy = "notify"
+	// PolicyIgnore does not watch branches at all
+	PolicyIgnore Policy = "ignore"
+)
+
+// ParsePolicy validates a policy name from the config.
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case PolicyAutoSwitch, PolicyNotify, PolicyIgnore:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown policy %q, use auto-switch, notify or ignore", s)
+	}
+}
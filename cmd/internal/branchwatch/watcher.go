@@ -0,0 +1,210 @@
+// Package branchwatch follows branch checkouts in the repositories the daemon
+// knows about and moves tracking along with them.
+package branchwatch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gen2brain/beeep"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/ipc"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+)
+
+// rescanInterval is how often the set of watched repositories is refreshed,
+// picking up sessions started in a repository that was not watched yet.
+const rescanInterval = 10 * time.Second
+
+// Watcher watches HEAD of the repository of the active session and of the
+// configured repositories, and applies Policy when another branch is checked
+// out there.
+type Watcher struct {
+	Tracker tracker.Tracker
+	// SwitchIn moves tracking to branch in repo
+	SwitchIn func(repo git.Repository, branch string) (tracker.SessionStatus, error)
+	Policy   Policy
+	// Debounce is how long HEAD must stay unchanged before a checkout is
+	// acted upon, so rebases and quick back-and-forth checkouts settle first
+	Debounce time.Duration
+	// PollInterval is used where file notifications are unavailable
+	PollInterval time.Duration
+	// Repositories are always watched, in addition to the active session's
+	Repositories  []string
+	Notifications bool
+	// Events, if set, makes a newly started session's repository watched
+	// right away instead of on the next rescan
+	Events <-chan ipc.Event
+
+	// watched maps a git directory to the top-level directory of its repository
+	watched  map[string]string
+	notified map[string]string
+}
+
+// Start implements afk.Watcher.
+func (w *Watcher) Start(ctx context.Context) error {
+	if w.Policy == PolicyIgnore {
+		return nil
+	}
+
+	n := newNotifier(w.PollInterval)
+	defer n.Close()
+
+	w.watched = map[string]string{}
+	w.notified = map[string]string{}
+
+	rescan := time.NewTicker(rescanInterval)
+	defer rescan.Stop()
+
+	timers := map[string]*time.Timer{}
+	settled := make(chan string)
+	// schedule acts on gitDir once its HEAD has not changed for Debounce
+	schedule := func(gitDir string) {
+		if timer, ok := timers[gitDir]; ok {
+			timer.Reset(w.Debounce)
+			return
+		}
+		timers[gitDir] = time.AfterFunc(w.Debounce, func() {
+			select {
+			case settled <- gitDir:
+			case <-ctx.Done():
+			}
+		})
+	}
+
+	for _, gitDir := range w.refresh(n) {
+		schedule(gitDir)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			for _, timer := range timers {
+				timer.Stop()
+			}
+			return nil
+		case <-rescan.C:
+			for _, gitDir := range w.refresh(n) {
+				schedule(gitDir)
+			}
+		case event := <-w.Events:
+			// Undo may reopen a session in a repository not watched yet
+			if event.Type == ipc.EventStarted || event.Type == ipc.EventUndone {
+				for _, gitDir := range w.refresh(n) {
+					schedule(gitDir)
+				}
+			}
+		case gitDir := <-n.Events():
+			schedule(gitDir)
+		case gitDir := <-settled:
+			delete(timers, gitDir)
+			if root, ok := w.watched[gitDir]; ok {
+				if err := w.checkout(root); err != nil {
+					fmt.Printf("Failed to follow branch change in %s: %v\n", root, err)
+				}
+			}
+			w.refresh(n)
+		}
+	}
+}
+
+// refresh watches the configured repositories and the repository of the
+// active session, and stops watching any other. It returns the git
+// directories it started watching: HEAD may have moved there before the
+// watch was set up, so they are checked once right away.
+func (w *Watcher) refresh(n notifier) []string {
+	dirs := append([]string{}, w.Repositories...)
+	if status, err := w.Tracker.Status(); err == nil && status.RepoPath != "" {
+		dirs = append(dirs, status.RepoPath)
+	}
+
+	wanted := map[string]string{}
+	for _, dir := range dirs {
+		repo, err := git.GetRepository(dir)
+		if err != nil {
+			continue
+		}
+		gitDir, err := git.GetGitDir(repo.Root)
+		if err != nil {
+			continue
+		}
+		wanted[gitDir] = repo.Root
+	}
+
+	for gitDir := range w.watched {
+		if _, ok := wanted[gitDir]; !ok {
+			n.Remove(gitDir)
+			delete(w.watched, gitDir)
+		}
+	}
+	var added []string
+	for gitDir, root := range wanted {
+		if _, ok := w.watched[gitDir]; ok {
+			continue
+		}
+		if err := n.Add(gitDir); err != nil {
+			fmt.Printf("Failed to watch %s: %v\n", root, err)
+			continue
+		}
+		w.watched[gitDir] = root
+		added = append(added, gitDir)
+	}
+	return added
+}
+
+// checkout applies the policy after HEAD of the repository at root settled.
+func (w *Watcher) checkout(root string) error {
+	branch, err := git.GetBranchName(root)
+	if err != nil {
+		return err
+	}
+	// A detached HEAD is a rebase, bisect or a look at an old commit, not
+	// work on another branch
+	if branch == "HEAD" {
+		return nil
+	}
+
+	status, err := w.Tracker.Status()
+	if err != nil {
+		// Nothing is tracked, so there is nothing to move
+		return nil
+	}
+	if status.RepoPath != root || status.Branch == branch {
+		return nil
+	}
+
+	if w.Policy == PolicyNotify || status.IsPaused {
+		if w.notified[root] == branch {
+			return nil
+		}
+		w.notified[root] = branch
+
+		message := fmt.Sprintf("Checked out '%s' while tracking '%s'", branch, status.Branch)
+		if status.IsPaused {
+			message += ", not switching a paused session"
+		}
+		fmt.Println(message)
+		w.notify(message)
+		return nil
+	}
+
+	repo, err := git.GetRepository(root)
+	if err != nil {
+		return err
+	}
+	if _, err := w.SwitchIn(repo, branch); err != nil {
+		return err
+	}
+
+	fmt.Printf("Switched tracking from '%s' to '%s' in %s\n", status.Branch, branch, root)
+	w.notify(fmt.Sprintf("Switched tracking from '%s' to '%s'", status.Branch, branch))
+	return nil
+}
+
+func (w *Watcher) notify(message string) {
+	if !w.Notifications {
+		return
+	}
+	beeep.Notify("Lofi Tracker", message, "")
+}
@@ -0,0 +1,197 @@
+package branchwatch
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+)
+
+func gitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	args = append([]string{"-C", dir, "-c", "user.name=lofi", "-c", "user.email=lofi@example.com"}, args...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+func initTestRepo(t *testing.T) git.Repository {
+	t.Helper()
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q", "-b", "main")
+	gitCmd(t, dir, "commit", "-q", "--allow-empty", "-m", "first")
+
+	repo, err := git.GetRepository(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return repo
+}
+
+// startWatcher tracks main in repo and runs a watcher with policy on it.
+func startWatcher(t *testing.T, repo git.Repository, policy Policy) tracker.Tracker {
+	t.Helper()
+
+	database, err := db.NewSQLiteDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("expected no error opening database, got %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	tr := tracker.NewTracker(repo, database)
+	if err := tr.Start("main"); err != nil {
+		t.Fatalf("expected no error starting, got %v", err)
+	}
+
+	w := &Watcher{
+		Tracker: tr,
+		SwitchIn: func(repo git.Repository, branch string) (tracker.SessionStatus, error) {
+			return tracker.NewTracker(repo, database).Switch(branch)
+		},
+		Policy:       policy,
+		Debounce:     50 * time.Millisecond,
+		PollInterval: 20 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Start(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	// Let the watcher pick up the repository of the active session
+	time.Sleep(100 * time.Millisecond)
+	return tr
+}
+
+func waitForBranch(t *testing.T, tr tracker.Tracker, want string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, err := tr.Status()
+		if err == nil && status.Branch == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected tracking to move to %s, got %+v (%v)", want, status, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestWatcher_WhenBranchIsCheckedOut_ShouldSwitchTracking(t *testing.T) {
+	repo := initTestRepo(t)
+	tr := startWatcher(t, repo, PolicyAutoSwitch)
+
+	gitCmd(t, repo.Root, "checkout", "-q", "-b", "feature/ABC-7")
+
+	waitForBranch(t, tr, "feature/ABC-7")
+
+	status, err := tr.Status()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.RepoPath != repo.Root || status.Ticket != "ABC-7" {
+		t.Errorf("expected a session on ABC-7 in %s, got %+v", repo.Root, status)
+	}
+}
+
+func TestWatcher_WhenPolicyIsNotify_ShouldKeepTracking(t *testing.T) {
+	repo := initTestRepo(t)
+	tr := startWatcher(t, repo, PolicyNotify)
+
+	gitCmd(t, repo.Root, "checkout", "-q", "-b", "feature/other")
+	time.Sleep(500 * time.Millisecond)
+
+	status, err := tr.Status()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.Branch != "main" {
+		t.Errorf("expected tracking to stay on main, got %s", status.Branch)
+	}
+}
+
+func TestWatcher_WhenHeadIsDetached_ShouldKeepTracking(t *testing.T) {
+	repo := initTestRepo(t)
+	tr := startWatcher(t, repo, PolicyAutoSwitch)
+
+	gitCmd(t, repo.Root, "checkout", "-q", "--detach")
+	time.Sleep(500 * time.Millisecond)
+
+	status, err := tr.Status()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.Branch != "main" {
+		t.Errorf("expected tracking to stay on main, got %s", status.Branch)
+	}
+}
+
+func TestPoller_ShouldReportChangedHead(t *testing.T) {
+	repo := initTestRepo(t)
+	gitDir, err := git.GetGitDir(repo.Root)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	p := newPoller(10 * time.Millisecond)
+	defer p.Close()
+	if err := p.Add(gitDir); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	gitCmd(t, repo.Root, "checkout", "-q", "-b", "feature")
+
+	select {
+	case changed := <-p.Events():
+		if changed != gitDir {
+			t.Errorf("expected a change in %s, got %s", gitDir, changed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a HEAD change, got none")
+	}
+}
+
+func TestNotifier_ShouldReportChangedHead(t *testing.T) {
+	repo := initTestRepo(t)
+	gitDir, err := git.GetGitDir(repo.Root)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Polling once an hour, a change is only seen in time through file
+	// notifications where the platform has them.
+	n := newNotifier(time.Hour)
+	defer n.Close()
+	if _, polling := n.(*poller); polling {
+		t.Skip("no file notifications on this platform")
+	}
+	if err := n.Add(gitDir); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	gitCmd(t, repo.Root, "checkout", "-q", "-b", "feature")
+
+	select {
+	case changed := <-n.Events():
+		if changed != gitDir {
+			t.Errorf("expected a change in %s, got %s", gitDir, changed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a HEAD change, got none")
+	}
+}
@@ -1 +1,458 @@
 package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/impactj90/lofi-tracker/cmd/internal/ticket"
+	"github.com/impactj90/lofi-tracker/cmd/internal/weekday"
+)
+
+// RepoFileName is the per-repository config file, looked up in the top-level
+// directory of the repository.
+const RepoFileName = ".lofi-tracker.toml"
+
+// Source names the layer a setting's effective value comes from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceRepo    Source = "repo"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Config holds every setting of the CLI and the daemon. Values are layered:
+// built-in defaults, then the config file, then the repository's
+// .lofi-tracker.toml, then LOFI_TRACKER_* environment variables, then command
+// line flags applied by the commands themselves.
+type Config struct {
+	DBPath        string              `toml:"db_path"`
+	Idle          IdleConfig          `toml:"idle"`
+	Notifications NotificationsConfig `toml:"notifications"`
+	Watch         WatchConfig         `toml:"watch"`
+	Ticket        TicketConfig        `toml:"ticket"`
+	Project       ProjectConfig       `toml:"project"`
+	Report        ReportConfig        `toml:"report"`
+	Jira          JiraConfig          `toml:"jira"`
+
+	sources map[string]Source
+}
+
+type IdleConfig struct {
+	// Threshold is how long without input before a session is paused as AFK
+	Threshold Duration `toml:"threshold"`
+	// PollInterval is how often the daemon checks for input while AFK
+	PollInterval Duration `toml:"poll_interval"`
+}
+
+type NotificationsConfig struct {
+	Enabled bool `toml:"enabled"`
+}
+
+// WatchConfig controls how the daemon follows branch checkouts.
+type WatchConfig struct {
+	// Policy is auto-switch, notify or ignore
+	Policy string `toml:"policy"`
+	// Debounce is how long HEAD must settle before a checkout is acted upon
+	Debounce Duration `toml:"debounce"`
+	// PollInterval is used where file notifications are unavailable
+	PollInterval Duration `toml:"poll_interval"`
+	// Repositories are watched even while no session is tracked in them
+	Repositories []string `toml:"repositories"`
+}
+
+type TicketConfig struct {
+	Patterns []string `toml:"patterns"`
+	Fallback string   `toml:"fallback"`
+}
+
+// ProjectConfig describes the work done in a repository. It is mostly set in
+// a repository's .lofi-tracker.toml.
+type ProjectConfig struct {
+	Client string `toml:"client"`
+	Name   string `toml:"name"`
+	// Tags are added to every session started in the repository
+	Tags []string `toml:"tags"`
+	// Tracking set to false refuses to start sessions in the repository
+	Tracking bool `toml:"tracking"`
+}
+
+type ReportConfig struct {
+	// Range is the default report range: day, week or month
+	Range     string `toml:"range"`
+	WeekStart string `toml:"week_start"`
+	GroupBy   string `toml:"group_by"`
+}
+
+type JiraConfig struct {
+	BaseURL     string   `toml:"base_url"`
+	Email       string   `toml:"email"`
+	APIToken    string   `toml:"api_token"`
+	RoundTo     Duration `toml:"round_to"`
+	RoundMode   string   `toml:"round_mode"`
+	MinDuration Duration `toml:"min_duration"`
+}
+
+// Default returns the built-in configuration.
+func Default() *Config {
+	return &Config{
+		DBPath: "~/.lofi-tracker/lofi-tracker.db",
+		Idle: IdleConfig{
+			Threshold:    Duration(15 * time.Minute),
+			PollInterval: Duration(2 * time.Second),
+		},
+		Notifications: NotificationsConfig{Enabled: true},
+		Watch: WatchConfig{
+			Policy:       "auto-switch",
+			Debounce:     Duration(3 * time.Second),
+			PollInterval: Duration(2 * time.Second),
+		},
+		Ticket: TicketConfig{
+			Patterns: []string{ticket.DefaultPattern},
+			Fallback: ticket.DefaultFallback,
+		},
+		Project: ProjectConfig{Tracking: true},
+		Report: ReportConfig{
+			Range:     "day",
+			WeekStart: "monday",
+			GroupBy:   "branch",
+		},
+		Jira: JiraConfig{
+			RoundMode:   "up",
+			MinDuration: Duration(time.Minute),
+		},
+	}
+}
+
+// Path returns the location of the config file: $LOFI_TRACKER_CONFIG if set,
+// otherwise lofi-tracker/config.toml below $XDG_CONFIG_HOME or ~/.config.
+func Path() (string, error) {
+	if p := os.Getenv("LOFI_TRACKER_CONFIG"); p != "" {
+		return p, nil
+	}
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(dir, "lofi-tracker", "config.toml"), nil
+}
+
+// Load returns the defaults overlaid with the config file at path, if it
+// exists, and with the environment.
+func Load(path string) (*Config, error) {
+	return LoadWithRepo(path, "")
+}
+
+// LoadWithRepo is Load with the .lofi-tracker.toml of the repository at
+// repoRoot layered between the config file and the environment.
+func LoadWithRepo(path, repoRoot string) (*Config, error) {
+	cfg, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if repoRoot != "" {
+		if err := cfg.applyFile(filepath.Join(repoRoot, RepoFileName), SourceRepo, repoKeyAllowed); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// LoadFile returns the defaults overlaid with the config file at path only.
+// A missing file is not an error, unknown keys are.
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+	if err := cfg.applyFile(path, SourceFile, nil); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// repoKeyAllowed limits what a repository may configure. Anything that would
+// let a cloned repository redirect the database or credentials is refused.
+func repoKeyAllowed(key string) bool {
+	switch {
+	case strings.HasPrefix(key, "ticket."), strings.HasPrefix(key, "project."), strings.HasPrefix(key, "report."):
+		return true
+	case key == "jira.round_to", key == "jira.round_mode", key == "jira.min_duration":
+		return true
+	}
+	return false
+}
+
+// applyFile overlays the settings present in the file at path. When allowed
+// is set, keys it rejects make the whole file invalid.
+func (c *Config) applyFile(path string, source Source, allowed func(key string) bool) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	meta, err := toml.Decode(string(content), Default())
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, k := range undecoded {
+			keys[i] = k.String()
+		}
+		return fmt.Errorf("%s: unknown keys %s", path, strings.Join(keys, ", "))
+	}
+
+	var defined []string
+	for _, key := range Keys() {
+		if !meta.IsDefined(strings.Split(key, ".")...) {
+			continue
+		}
+		if allowed != nil && !allowed(key) {
+			return fmt.Errorf("%s: %s cannot be set per repository", path, key)
+		}
+		defined = append(defined, key)
+	}
+
+	if _, err := toml.Decode(string(content), c); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, key := range defined {
+		c.setSource(key, source)
+	}
+
+	return nil
+}
+
+// Source returns the layer the effective value of key comes from.
+func (c *Config) Source(key string) Source {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// Override sets key from a command line flag.
+func (c *Config) Override(key string, values ...string) error {
+	if err := c.Set(key, values...); err != nil {
+		return err
+	}
+	c.setSource(key, SourceFlag)
+	return nil
+}
+
+func (c *Config) setSource(key string, source Source) {
+	if c.sources == nil {
+		c.sources = map[string]Source{}
+	}
+	c.sources[key] = source
+}
+
+// Save writes cfg to path, creating its directory.
+func Save(path string, cfg *Config) error {
+	return writeFile(path, cfg)
+}
+
+// SaveKey writes the value cfg has for key to the config file at path. The
+// file's other settings stay as they are, so defaults and overrides that only
+// live in cfg are not written to it.
+func SaveKey(path string, cfg *Config, key string) error {
+	v, err := cfg.field(key)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]any{}
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if _, err := toml.Decode(string(content), &values); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	table := values
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		sub, ok := table[part].(map[string]any)
+		if !ok {
+			sub = map[string]any{}
+			table[part] = sub
+		}
+		table = sub
+	}
+	table[parts[len(parts)-1]] = v.Interface()
+
+	return writeFile(path, values)
+}
+
+func writeFile(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := toml.NewEncoder(f).Encode(v); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// envAliases are environment variables honoured besides the generated
+// LOFI_TRACKER_* names, for tools that already export them.
+var envAliases = map[string]string{
+	"jira.base_url":  "JIRA_BASE_URL",
+	"jira.email":     "JIRA_EMAIL",
+	"jira.api_token": "JIRA_API_TOKEN",
+}
+
+// EnvName returns the environment variable overriding key, e.g.
+// LOFI_TRACKER_IDLE_THRESHOLD for idle.threshold.
+func EnvName(key string) string {
+	return "LOFI_TRACKER_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	for _, key := range Keys() {
+		value, ok := lookup(EnvName(key))
+		if !ok {
+			if alias, hasAlias := envAliases[key]; hasAlias {
+				value, ok = lookup(alias)
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		if err := c.Set(key, envValues(c, key, value)...); err != nil {
+			return fmt.Errorf("%s: %w", EnvName(key), err)
+		}
+		c.setSource(key, SourceEnv)
+	}
+	return nil
+}
+
+// envValues splits the value of a list key into one element per line, as
+// patterns and paths may well contain spaces or commas. Other keys take the
+// value as it is, empty or not.
+func envValues(c *Config, key, value string) []string {
+	if v, err := c.field(key); err != nil || v.Kind() != reflect.Slice {
+		return []string{value}
+	}
+
+	var values []string
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			values = append(values, line)
+		}
+	}
+	return values
+}
+
+// Validate reports the first invalid setting.
+func (c *Config) Validate() error {
+	if c.DBPath == "" {
+		return errors.New("db_path must not be empty")
+	}
+	if c.Idle.Threshold <= 0 {
+		return errors.New("idle.threshold must be positive")
+	}
+	if c.Idle.PollInterval <= 0 {
+		return errors.New("idle.poll_interval must be positive")
+	}
+	if _, err := c.TicketExtractor(); err != nil {
+		return fmt.Errorf("ticket.patterns: %w", err)
+	}
+	switch c.Watch.Policy {
+	case "auto-switch", "notify", "ignore":
+	default:
+		return fmt.Errorf("watch.policy: unknown policy %q, use auto-switch, notify or ignore", c.Watch.Policy)
+	}
+	if c.Watch.Debounce < 0 || c.Watch.PollInterval <= 0 {
+		return errors.New("watch.debounce must not be negative and watch.poll_interval must be positive")
+	}
+	switch c.Report.Range {
+	case "day", "week", "month":
+	default:
+		return fmt.Errorf("report.range: unknown range %q, use day, week or month", c.Report.Range)
+	}
+	if _, err := weekday.Parse(c.Report.WeekStart); err != nil {
+		return fmt.Errorf("report.week_start: %w", err)
+	}
+	switch c.Report.GroupBy {
+	case "branch", "ticket", "project", "client":
+	default:
+		return fmt.Errorf("report.group_by: unknown grouping %q, use branch, ticket, project or client", c.Report.GroupBy)
+	}
+	switch strings.ToLower(c.Jira.RoundMode) {
+	case "", "up", "nearest", "down":
+	default:
+		return fmt.Errorf("jira.round_mode: unknown rounding mode %q, use up, nearest or down", c.Jira.RoundMode)
+	}
+	if c.Jira.RoundTo < 0 || c.Jira.MinDuration < 0 {
+		return errors.New("jira.round_to and jira.min_duration must not be negative")
+	}
+	return nil
+}
+
+// TicketExtractor returns the extractor for the configured ticket patterns.
+func (c *Config) TicketExtractor() (*ticket.Extractor, error) {
+	return ticket.NewExtractor(c.Ticket.Patterns, c.Ticket.Fallback)
+}
+
+// ResolvedDBPath returns DBPath with a leading ~ expanded.
+func (c *Config) ResolvedDBPath() (string, error) {
+	return expandHome(c.DBPath)
+}
+
+// WatchedRepositories returns Watch.Repositories with a leading ~ expanded.
+func (c *Config) WatchedRepositories() ([]string, error) {
+	dirs := make([]string, 0, len(c.Watch.Repositories))
+	for _, dir := range c.Watch.Repositories {
+		expanded, err := expandHome(dir)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, expanded)
+	}
+	return dirs, nil
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
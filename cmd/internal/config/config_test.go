@@ -0,0 +1,283 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("expected no error writing config, got %v", err)
+	}
+	return path
+}
+
+func TestLoad_ShouldLayerDefaultsFileAndEnvironment(t *testing.T) {
+	path := writeConfig(t, `
+db_path = "/data/lofi.db"
+
+[idle]
+threshold = "10m"
+
+[ticket]
+patterns = ["PROJ-[0-9]+"]
+`)
+	t.Setenv("LOFI_TRACKER_IDLE_THRESHOLD", "20m")
+	t.Setenv("LOFI_TRACKER_NOTIFICATIONS_ENABLED", "false")
+	t.Setenv("JIRA_BASE_URL", "https://example.atlassian.net")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.DBPath != "/data/lofi.db" {
+		t.Errorf("expected db_path from the file, got %q", cfg.DBPath)
+	}
+	if time.Duration(cfg.Idle.Threshold) != 20*time.Minute {
+		t.Errorf("expected the environment to override the file, got %v", cfg.Idle.Threshold)
+	}
+	if time.Duration(cfg.Idle.PollInterval) != 2*time.Second {
+		t.Errorf("expected the default poll interval, got %v", cfg.Idle.PollInterval)
+	}
+	if cfg.Notifications.Enabled {
+		t.Errorf("expected notifications to be disabled by the environment")
+	}
+	if len(cfg.Ticket.Patterns) != 1 || cfg.Ticket.Patterns[0] != "PROJ-[0-9]+" {
+		t.Errorf("expected ticket patterns from the file, got %v", cfg.Ticket.Patterns)
+	}
+	if cfg.Jira.BaseURL != "https://example.atlassian.net" {
+		t.Errorf("expected JIRA_BASE_URL to be honoured, got %q", cfg.Jira.BaseURL)
+	}
+}
+
+func TestLoad_WhenEnvValueHasSpaces_ShouldKeepItWhole(t *testing.T) {
+	t.Setenv("LOFI_TRACKER_DB_PATH", "/tmp/a b/x.db")
+	t.Setenv("LOFI_TRACKER_TICKET_FALLBACK", "(no ticket)")
+	t.Setenv("LOFI_TRACKER_TICKET_PATTERNS", "ABC-[0-9]+\nfix ([0-9]+)\n")
+
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.DBPath != "/tmp/a b/x.db" {
+		t.Errorf("expected the path with its space, got %q", cfg.DBPath)
+	}
+	if cfg.Ticket.Fallback != "(no ticket)" {
+		t.Errorf("expected the fallback with its space, got %q", cfg.Ticket.Fallback)
+	}
+	if len(cfg.Ticket.Patterns) != 2 || cfg.Ticket.Patterns[1] != "fix ([0-9]+)" {
+		t.Errorf("expected one pattern per line, got %q", cfg.Ticket.Patterns)
+	}
+}
+
+func TestLoad_WhenEnvValueIsEmpty_ShouldSetItEmpty(t *testing.T) {
+	path := writeConfig(t, "[project]\nclient = \"acme\"\n")
+	t.Setenv("LOFI_TRACKER_PROJECT_CLIENT", "")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.Project.Client != "" || cfg.Source("project.client") != SourceEnv {
+		t.Errorf("expected the environment to clear the client, got %q from %s", cfg.Project.Client, cfg.Source("project.client"))
+	}
+}
+
+func TestLoad_WhenFileIsMissing_ShouldUseDefaults(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected the defaults to be valid, got %v", err)
+	}
+}
+
+func TestLoadFile_WhenKeyIsUnknown_ShouldFail(t *testing.T) {
+	path := writeConfig(t, "[idle]\nthreshhold = \"10m\"\n")
+
+	_, err := LoadFile(path)
+	if err == nil || !strings.Contains(err.Error(), "idle.threshhold") {
+		t.Errorf("expected an unknown key error naming idle.threshhold, got %v", err)
+	}
+}
+
+func TestSetAndGet_ShouldRoundTripThroughTheFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.toml")
+
+	cfg := Default()
+	if err := cfg.Set("idle.threshold", "12m"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := cfg.Set("ticket.patterns", "ABC-[0-9]+", "#([0-9]+)"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := cfg.Set("notifications.enabled", "nope"); err == nil {
+		t.Errorf("expected an error for an invalid bool")
+	}
+	if err := cfg.Set("idle.threshold", "1m", "2m"); err == nil {
+		t.Errorf("expected an error for several values on a scalar key")
+	}
+	if err := cfg.Set("does.not.exist", "x"); err == nil {
+		t.Errorf("expected an error for an unknown key")
+	}
+
+	if err := Save(path, cfg); err != nil {
+		t.Fatalf("expected no error saving, got %v", err)
+	}
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("expected no error loading, got %v", err)
+	}
+
+	if got, _ := loaded.Get("idle.threshold"); got != "12m0s" {
+		t.Errorf("expected 12m0s, got %q", got)
+	}
+	if got, _ := loaded.Get("ticket.patterns"); got != "ABC-[0-9]+ #([0-9]+)" {
+		t.Errorf("expected both patterns, got %q", got)
+	}
+}
+
+func TestSaveKey_ShouldOnlyWriteTheKeyToTheFile(t *testing.T) {
+	path := writeConfig(t, "[idle]\nthreshold = \"10m\"\n")
+	t.Setenv("LOFI_TRACKER_DB_PATH", "/env/lofi.db")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := cfg.Set("ticket.patterns", "ABC-[0-9]+"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := SaveKey(path, cfg, "ticket.patterns"); err != nil {
+		t.Fatalf("expected no error saving, got %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected no error reading, got %v", err)
+	}
+	for _, unwanted := range []string{"db_path", "poll_interval", "week_start"} {
+		if strings.Contains(string(content), unwanted) {
+			t.Errorf("expected %s to stay out of the file, got\n%s", unwanted, content)
+		}
+	}
+
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("expected no error loading, got %v", err)
+	}
+	if got, _ := loaded.Get("idle.threshold"); got != "10m0s" {
+		t.Errorf("expected the file's threshold to be kept, got %q", got)
+	}
+	if got, _ := loaded.Get("ticket.patterns"); got != "ABC-[0-9]+" {
+		t.Errorf("expected the new patterns, got %q", got)
+	}
+}
+
+func TestValidate_ShouldRejectInvalidSettings(t *testing.T) {
+	tests := map[string]string{
+		"idle.threshold":    "0s",
+		"ticket.patterns":   "([A-Z",
+		"report.range":      "year",
+		"report.week_start": "someday",
+		"report.group_by":   "repo",
+		"jira.round_mode":   "sideways",
+	}
+
+	for key, value := range tests {
+		cfg := Default()
+		if err := cfg.Set(key, value); err != nil {
+			t.Fatalf("expected %s to be settable, got %v", key, err)
+		}
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), key) {
+			t.Errorf("expected a validation error for %s=%s, got %v", key, value, err)
+		}
+	}
+}
+
+func TestKeys_ShouldListNestedKeys(t *testing.T) {
+	keys := strings.Join(Keys(), ",")
+	for _, want := range []string{"db_path", "idle.threshold", "idle.poll_interval", "notifications.enabled", "ticket.patterns", "report.week_start", "jira.api_token"} {
+		if !strings.Contains(keys, want) {
+			t.Errorf("expected %s in keys %s", want, keys)
+		}
+	}
+}
+
+func TestLoadWithRepo_ShouldLayerRepositoryFileBetweenFileAndEnvironment(t *testing.T) {
+	path := writeConfig(t, `
+[ticket]
+patterns = ["PROJ-[0-9]+"]
+
+[jira]
+round_to = "15m"
+`)
+	repoRoot := t.TempDir()
+	repoFile := `
+[ticket]
+patterns = ["ACME-[0-9]+"]
+
+[project]
+client = "Acme"
+name = "Webshop"
+tags = ["billable"]
+
+[jira]
+round_to = "30m"
+`
+	if err := os.WriteFile(filepath.Join(repoRoot, RepoFileName), []byte(repoFile), 0600); err != nil {
+		t.Fatalf("expected no error writing repo config, got %v", err)
+	}
+	t.Setenv("LOFI_TRACKER_JIRA_ROUND_TO", "1h")
+
+	cfg, err := LoadWithRepo(path, repoRoot)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(cfg.Ticket.Patterns) != 1 || cfg.Ticket.Patterns[0] != "ACME-[0-9]+" {
+		t.Errorf("expected ticket patterns from the repository, got %v", cfg.Ticket.Patterns)
+	}
+	if cfg.Project.Client != "Acme" || cfg.Project.Name != "Webshop" || !cfg.Project.Tracking {
+		t.Errorf("expected project from the repository with tracking on, got %+v", cfg.Project)
+	}
+	if time.Duration(cfg.Jira.RoundTo) != time.Hour {
+		t.Errorf("expected the environment to override the repository, got %v", cfg.Jira.RoundTo)
+	}
+
+	sources := map[string]Source{
+		"ticket.patterns":  SourceRepo,
+		"project.client":   SourceRepo,
+		"jira.round_to":    SourceEnv,
+		"idle.threshold":   SourceDefault,
+		"project.tracking": SourceDefault,
+	}
+	for key, want := range sources {
+		if got := cfg.Source(key); got != want {
+			t.Errorf("expected %s to come from %s, got %s", key, want, got)
+		}
+	}
+}
+
+func TestLoadWithRepo_WhenRepositorySetsRestrictedKey_ShouldFail(t *testing.T) {
+	repoRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repoRoot, RepoFileName), []byte("[jira]\napi_token = \"secret\"\n"), 0600); err != nil {
+		t.Fatalf("expected no error writing repo config, got %v", err)
+	}
+
+	_, err := LoadWithRepo(filepath.Join(t.TempDir(), "missing.toml"), repoRoot)
+	if err == nil || !strings.Contains(err.Error(), "jira.api_token") {
+		t.Errorf("expected an error naming jira.api_token, got %v", err)
+	}
+}
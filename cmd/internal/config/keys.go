@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Keys returns every settable key in dotted form, e.g. "idle.threshold",
+// in the order they appear in Config.
+func Keys() []string {
+	var keys []string
+	walk(reflect.ValueOf(Default()).Elem(), "", func(key string, _ reflect.Value) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// Get returns the value of key formatted as in the config file; lists are
+// joined with spaces.
+func (c *Config) Get(key string) (string, error) {
+	v, err := c.field(key)
+	if err != nil {
+		return "", err
+	}
+
+	switch value := v.Interface().(type) {
+	case Duration:
+		return value.String(), nil
+	case []string:
+		return strings.Join(value, " "), nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+// Set parses values into key. Only list keys accept more than one value.
+func (c *Config) Set(key string, values ...string) error {
+	v, err := c.field(key)
+	if err != nil {
+		return err
+	}
+
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.ValueOf(append([]string(nil), values...)))
+		return nil
+	}
+
+	if len(values) != 1 {
+		return fmt.Errorf("%s takes exactly one value", key)
+	}
+	value := values[0]
+
+	switch v.Interface().(type) {
+	case Duration:
+		var d Duration
+		if err := d.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		v.Set(reflect.ValueOf(d))
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: expected true or false, got %q", key, value)
+		}
+		v.SetBool(b)
+	case string:
+		v.SetString(value)
+	default:
+		return fmt.Errorf("%s: unsupported type %s", key, v.Type())
+	}
+
+	return nil
+}
+
+func (c *Config) field(key string) (reflect.Value, error) {
+	var found reflect.Value
+	walk(reflect.ValueOf(c).Elem(), "", func(k string, v reflect.Value) {
+		if k == key {
+			found = v
+		}
+	})
+	if !found.IsValid() {
+		return reflect.Value{}, fmt.Errorf("unknown config key %q", key)
+	}
+	return found, nil
+}
+
+// walk calls fn for every leaf field of v with its dotted toml key.
+func walk(v reflect.Value, prefix string, fn func(key string, v reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("toml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		key := prefix + name
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			walk(field, key+".", fn)
+			continue
+		}
+		fn(key, field)
+	}
+}
@@ -0,0 +1,158 @@
+package daemon
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestHelperDaemon is not a test: Start re-executes the test binary with
+// LOFI_TEST_DAEMON set to run it as a stand-in for lofi-daemon.
+func TestHelperDaemon(t *testing.T) {
+	if os.Getenv("LOFI_TEST_DAEMON") != "1" {
+		t.Skip("helper process")
+	}
+
+	var pidPath string
+	for i, arg := range os.Args {
+		if arg == "--pid-file" && i+1 < len(os.Args) {
+			pidPath = os.Args[i+1]
+		}
+	}
+
+	pid, err := AcquirePIDFile(pidPath)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM)
+	select {
+	case <-sig:
+	case <-time.After(30 * time.Second):
+	}
+
+	pid.Release()
+	os.Exit(0)
+}
+
+func TestAcquirePIDFile_WhenAlreadyHeld_ShouldRefuse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.pid")
+
+	pid, err := AcquirePIDFile(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer pid.Release()
+
+	content, _ := os.ReadFile(path)
+	if strings.TrimSpace(string(content)) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("expected the PID file to hold our pid, got %q", content)
+	}
+
+	if _, err := AcquirePIDFile(path); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("expected ErrAlreadyRunning, got %v", err)
+	}
+
+	status, err := ReadStatus(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !status.Running || status.PID != os.Getpid() {
+		t.Errorf("expected running with our pid, got %+v", status)
+	}
+}
+
+func TestReadStatus_WhenNobodyHoldsTheFile_ShouldReportStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.pid")
+	if err := os.WriteFile(path, []byte("999999\n"), 0600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	status, err := ReadStatus(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.Running || !status.Stale || status.PID != 999999 {
+		t.Errorf("expected a stale PID file for 999999, got %+v", status)
+	}
+
+	pid, err := AcquirePIDFile(path)
+	if err != nil {
+		t.Fatalf("expected a stale PID file to be taken over, got %v", err)
+	}
+	pid.Release()
+
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected Release to remove the PID file, got %v", err)
+	}
+}
+
+func TestStartAndStop_ShouldManageTheDaemonProcess(t *testing.T) {
+	dir := t.TempDir()
+	pidPath := filepath.Join(dir, "daemon.pid")
+	logPath := filepath.Join(dir, "daemon.log")
+	t.Setenv("LOFI_TEST_DAEMON", "1")
+
+	args := []string{"-test.run=^TestHelperDaemon$", "--"}
+	pid, err := Start(os.Args[0], args, pidPath, logPath, 10*time.Second)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pid <= 0 {
+		t.Fatalf("expected a pid, got %d", pid)
+	}
+
+	if _, err := Start(os.Args[0], args, pidPath, logPath, 10*time.Second); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("expected a second daemon to be refused, got %v", err)
+	}
+
+	if err := Stop(pidPath, 10*time.Second); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := Stop(pidPath, time.Second); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning once stopped, got %v", err)
+	}
+}
+
+func TestRotatingLog_ShouldRotateAndKeepBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.log")
+
+	l, err := OpenRotatingLog(path, 10, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer l.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := l.Write([]byte(line)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	expected := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for file, want := range expected {
+		got, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("expected %s to exist, got %v", file, err)
+		}
+		if string(got) != want {
+			t.Errorf("expected %s to hold %q, got %q", file, want, got)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected only 2 backups, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package daemon
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// RotatingLog is an append-only log file that is rotated once it grows past
+// MaxSize: log becomes log.1, log.1 becomes log.2 and so on, keeping at most
+// Backups old files.
+type RotatingLog struct {
+	Path    string
+	MaxSize int64
+	Backups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenRotatingLog opens the log file at path for appending.
+func OpenRotatingLog(path string, maxSize int64, backups int) (*RotatingLog, error) {
+	l := &RotatingLog{Path: path, MaxSize: maxSize, Backups: backups}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Write implements io.Writer. A write is never split across files, so a file
+// may exceed MaxSize by the length of its last write.
+func (l *RotatingLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if l.MaxSize > 0 && l.size > 0 && l.size+int64(len(p)) > l.MaxSize {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// Close closes the current log file.
+func (l *RotatingLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *RotatingLog) open() error {
+	file, err := os.OpenFile(l.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+func (l *RotatingLog) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	l.file = nil
+
+	if l.Backups <= 0 {
+		if err := os.Remove(l.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return l.open()
+	}
+
+	for i := l.Backups - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", l.Path, i)
+		if err := os.Rename(from, fmt.Sprintf("%s.%d", l.Path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(l.Path, l.Path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return l.open()
+}
+
+// RedirectOutput sends everything the process writes to os.Stdout and
+// os.Stderr to w, one timestamped line at a time. The returned function
+// restores the original files and flushes what is still buffered.
+func RedirectOutput(w io.Writer) (func(), error) {
+	r, pw, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = pw, pw
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			fmt.Fprintf(w, "%s %s\n", time.Now().Format(time.RFC3339), scanner.Text())
+		}
+		r.Close()
+	}()
+
+	return func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		pw.Close()
+		<-done
+	}, nil
+}
@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	pidFileName       = "lofi-daemon.pid"
+	logFileName       = "lofi-daemon.log"
+	socketFileName    = "lofi-daemon.sock"
+	heartbeatFileName = "heartbeat"
+
+	// LogMaxSize and LogBackups bound the disk space taken by daemon logs.
+	LogMaxSize = 5 << 20
+	LogBackups = 3
+)
+
+// Dir returns the directory holding the daemon's PID and log files.
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".lofi-tracker"), nil
+}
+
+// PIDPath returns the default PID file of the daemon.
+func PIDPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, pidFileName), nil
+}
+
+// LogPath returns the default log file of the daemon.
+func LogPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, logFileName), nil
+}
+
+// SocketPath returns the default control socket of the daemon.
+func SocketPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, socketFileName), nil
+}
+
+// HeartbeatPath returns the file a shell hook touches on every prompt, the
+// idle time source of last resort.
+func HeartbeatPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, heartbeatFileName), nil
+}
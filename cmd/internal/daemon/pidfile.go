@@ -0,0 +1,153 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAlreadyRunning = errors.New("daemon is already running")
+	ErrNotRunning     = errors.New("daemon is not running")
+)
+
+// PIDFile is a PID file held locked by the running daemon. The lock, not the
+// file's existence, is what tells a live daemon from a stale file left behind
+// by a crash: the OS drops the lock when the process dies.
+type PIDFile struct {
+	path string
+	file *os.File
+}
+
+// AcquirePIDFile locks the PID file at path and writes the current PID into
+// it. It fails with ErrAlreadyRunning while another process holds the lock.
+func AcquirePIDFile(path string) (*PIDFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		if errors.Is(err, errLocked) {
+			if pid, err := readPID(path); err == nil {
+				return nil, fmt.Errorf("%w with pid %d", ErrAlreadyRunning, pid)
+			}
+			return nil, ErrAlreadyRunning
+		}
+		return nil, err
+	}
+
+	if err := file.Truncate(0); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &PIDFile{path: path, file: file}, nil
+}
+
+// Release removes the PID file and drops the lock.
+func (p *PIDFile) Release() error {
+	removeErr := os.Remove(p.path)
+	unlockFile(p.file)
+	closeErr := p.file.Close()
+	if removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+		return removeErr
+	}
+	return closeErr
+}
+
+// Status describes the daemon owning a PID file.
+type Status struct {
+	Running bool
+	PID     int
+	// Since is when the PID file was written, i.e. when the daemon started
+	Since time.Time
+	// Stale is set when a PID file was found without a live daemon holding it
+	Stale bool
+}
+
+// ReadStatus reports whether a daemon holds the PID file at path. A missing
+// file means not running; a file nobody holds a lock on is stale.
+func ReadStatus(path string) (Status, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Status{}, nil
+	}
+	if err != nil {
+		return Status{}, err
+	}
+
+	pid, _ := readPID(path)
+	held, err := isLocked(path)
+	if err != nil {
+		return Status{}, err
+	}
+
+	if !held {
+		return Status{PID: pid, Stale: true}, nil
+	}
+
+	return Status{Running: true, PID: pid, Since: info.ModTime()}, nil
+}
+
+// RemoveStale deletes the PID file at path unless a daemon holds it.
+func RemoveStale(path string) error {
+	status, err := ReadStatus(path)
+	if err != nil {
+		return err
+	}
+	if status.Running {
+		return fmt.Errorf("%w with pid %d", ErrAlreadyRunning, status.PID)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func readPID(path string) (int, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("%s: invalid pid %q", path, strings.TrimSpace(string(content)))
+	}
+	return pid, nil
+}
+
+// isLocked reports whether another process holds the lock on path.
+func isLocked(path string) (bool, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	if err := lockFile(file); err != nil {
+		if errors.Is(err, errLocked) {
+			return true, nil
+		}
+		return false, err
+	}
+	unlockFile(file)
+	return false, nil
+}
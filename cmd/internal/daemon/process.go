@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+const pollInterval = 50 * time.Millisecond
+
+// Start launches binary as a detached daemon owning the PID file at pidPath
+// and logging to logPath, and waits up to timeout for it to take the PID file
+// lock. A stale PID file is removed first; a live one fails with
+// ErrAlreadyRunning.
+func Start(binary string, args []string, pidPath, logPath string, timeout time.Duration) (int, error) {
+	if err := RemoveStale(pidPath); err != nil {
+		return 0, err
+	}
+
+	args = append(args, "--pid-file", pidPath, "--log-file", logPath)
+	cmd := exec.Command(binary, args...)
+	detach(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	deadline := time.After(timeout)
+	for {
+		select {
+		case err := <-exited:
+			if err == nil {
+				err = fmt.Errorf("exit status 0")
+			}
+			return 0, fmt.Errorf("daemon exited during startup (%v), see %s", err, logPath)
+		case <-deadline:
+			return 0, fmt.Errorf("daemon did not start within %s, see %s", timeout, logPath)
+		case <-time.After(pollInterval):
+		}
+
+		// The child writes its pid once it holds the lock, and exited tells
+		// whether it is still alive. Probing the lock instead would briefly
+		// take it and could make the child's own attempt fail.
+		if pid, err := readPID(pidPath); err == nil && pid == cmd.Process.Pid {
+			return pid, nil
+		}
+	}
+}
+
+// Stop asks the daemon holding the PID file at pidPath to shut down and waits
+// up to timeout for it to release the file. It fails with ErrNotRunning when
+// no daemon holds the file, removing a stale file on the way.
+func Stop(pidPath string, timeout time.Duration) error {
+	status, err := ReadStatus(pidPath)
+	if err != nil {
+		return err
+	}
+
+	if !status.Running {
+		if status.Stale {
+			if err := RemoveStale(pidPath); err != nil {
+				return err
+			}
+		}
+		return ErrNotRunning
+	}
+
+	process, err := os.FindProcess(status.PID)
+	if err != nil {
+		return err
+	}
+	if err := terminate(process); err != nil {
+		return fmt.Errorf("failed to signal pid %d: %w", status.PID, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		status, err := ReadStatus(pidPath)
+		if err != nil {
+			return err
+		}
+		if !status.Running {
+			return nil
+		}
+		time.Sleep(pollInterval)
+	}
+
+	return fmt.Errorf("daemon with pid %d did not stop within %s", status.PID, timeout)
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package daemon
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// detach starts cmd without a console, so it survives the terminal that
+// launched it.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS,
+	}
+}
+
+// terminate kills p: Windows has no SIGTERM, so the daemon cannot clean up,
+// but the OS releases its PID file lock.
+func terminate(p *os.Process) error {
+	return p.Kill()
+}
@@ -2,15 +2,36 @@ package db
 
 import "time"
 
+// SessionOrigin tells how a session came to be.
+type SessionOrigin string
+
+const (
+	// OriginTracked sessions were timed live with start and complete
+	OriginTracked SessionOrigin = "tracked"
+	// OriginManual sessions were entered afterwards with add
+	OriginManual SessionOrigin = "manual"
+)
+
 type Session struct {
-	ID        int64
-	Branch    string
-	StartTime time.Time
-	Endtime   *time.Time
-	IsPaused  bool
-	IsAfk     bool
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          int64
+	Branch      string
+	Ticket      string
+	Client      string
+	Project     string
+	RepoPath    string
+	RepoID      string
+	StartCommit string
+	EndCommit   string
+	StartTime   time.Time
+	Endtime     *time.Time
+	IsPaused    bool
+	IsAfk       bool
+	Origin      SessionOrigin
+	Tags        []string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	// Pauses is only populated by queries that load sessions with their pauses
+	Pauses []Pause
 }
 
 type Pause struct {
@@ -18,13 +39,121 @@ type Pause struct {
 	SessionID  int64
 	PauseStart time.Time
 	PauseEnd   *time.Time
+	IsAfk      bool
+	Reason     PauseReason
+	Note       string
+	// Decision is what became of the time of an AFK pause, see AwaitsDecision
+	Decision IdleDecision
+	// Activity labels kept idle time, such as "whiteboard" or "call"
+	Activity string
+}
+
+// Worklog records that a session was pushed to the issue tracker, so it is
+// never pushed twice.
+type Worklog struct {
+	SessionID int64
+	IssueKey  string
+	WorklogID string
+	Seconds   int
+	SyncedAt  time.Time
+	// Skipped says why the session was not pushed, in which case there is no
+	// WorklogID
+	Skipped string
+}
+
+// AuditAction is the kind of change an AuditEntry records.
+type AuditAction string
+
+const (
+	AuditEdit   AuditAction = "edit"
+	AuditDelete AuditAction = "delete"
+)
+
+// AuditEntry records a session as it was before and after it was edited by
+// hand. After is nil for a deleted session.
+type AuditEntry struct {
+	ID        int64
+	SessionID int64
+	Action    AuditAction
+	Before    *Session
+	After     *Session
+	ChangedAt time.Time
+}
+
+// OperationKind names a tracker action recorded in the operation journal.
+type OperationKind string
+
+const (
+	OpStart    OperationKind = "start"
+	OpPause    OperationKind = "pause"
+	OpResume   OperationKind = "resume"
+	OpComplete OperationKind = "complete"
+	OpSwitch   OperationKind = "switch"
+)
+
+// Operation is an entry of the journal that undo walks back through, written
+// in the same transaction as the change it records.
+type Operation struct {
+	ID        int64
+	Kind      OperationKind
+	SessionID int64
+	// Branch is the branch of SessionID, filled in when reading the journal
+	Branch string
+	// PauseID is the pause started by pause, ended by resume, or closed by
+	// complete and switch
+	PauseID int64
+	// NextSessionID is the session started by switch
+	NextSessionID int64
+	At            time.Time
 }
 
 type DB interface {
-	CreateSession(branch string, startTime time.Time) (int64, error)
-	CompleteSession(sessionID int64, endTime time.Time) error
+	CreateSession(session Session) (int64, error)
+	// AddSession inserts a completed session, refusing with
+	// ErrOverlappingSession if any other session overlaps it.
+	AddSession(session Session) (int64, error)
+	CompleteSession(sessionID int64, endTime time.Time, endCommit string) error
+	// SwitchSession completes a session and starts next at the same moment,
+	// in one transaction, and returns the ID of the new session.
+	SwitchSession(sessionID int64, at time.Time, endCommit string, next Session) (int64, error)
 	GetActiveSession() (*Session, error)
-	PauseSession(sessionID int64, pauseStart time.Time, isAfk bool) (int64, error)
+	// GetSession returns a session with its tags and pauses, or
+	// ErrSessionNotFound.
+	GetSession(sessionID int64) (*Session, error)
+	// UpdateSession replaces the branch, ticket, times, tags and pauses of a
+	// session after validating them, and records the change in the audit
+	// trail. Its operations can no longer be undone afterwards, and a sync
+	// that skipped it will consider it again.
+	UpdateSession(session Session) error
+	// DeleteSession removes a session with its pauses and tags, keeping a copy
+	// in the audit trail.
+	DeleteSession(sessionID int64) error
+	// ListAudit returns the audit trail of a session, or of all sessions for
+	// sessionID 0, oldest first.
+	ListAudit(sessionID int64) ([]AuditEntry, error)
+	// ListOperations returns up to limit journal entries that can still be
+	// undone, newest first.
+	ListOperations(limit int) ([]Operation, error)
+	// UndoLastOperation reverts the newest journal entry that was not undone
+	// yet and returns it, or ErrNothingToUndo.
+	UndoLastOperation() (Operation, error)
+	// RebuildFromEvents replaces all sessions and pauses by replaying the
+	// event log, and reports where the result differs from the tables. With
+	// dryRun the tables are left untouched.
+	RebuildFromEvents(dryRun bool) (RebuildResult, error)
+	// ListSessions returns the sessions matching filter with their tags and
+	// pauses loaded.
+	ListSessions(filter SessionFilter) ([]Session, error)
+	RecordWorklog(worklog Worklog) error
+	PauseSession(sessionID int64, pauseStart time.Time, reason PauseReason, note string) (int64, error)
 	ResumeSession(sessionID int64, pauseEnd time.Time) error
+	ListPauses(sessionID int64) ([]Pause, error)
+	// DecideIdle keeps or discards the time of an ended AFK pause as of at,
+	// or returns ErrPauseNotFound or ErrNotIdlePause. A pause can be decided
+	// again; activity only labels kept time.
+	DecideIdle(pauseID int64, at time.Time, decision IdleDecision, activity string) error
+	// ListPendingIdle returns the pauses that await a decision and ended
+	// after since, in open and completed sessions alike, oldest first.
+	ListPendingIdle(since time.Time) ([]Pause, error)
 	Close() error
 }
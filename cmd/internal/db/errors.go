@@ -8,5 +8,16 @@ var (
 	ErrFailedToCreateDirectoryForDatabase = errors.New("failed to create directory for database")
 	ErrFailedToOpenDatabase = errors.New("failed to open database")
 	ErrFailedToMigrateDatabase = errors.New("failed to migrate database")
+	ErrDatabaseVersionTooNew = errors.New("database was created by a newer lofi-tracker, please upgrade")
+	ErrInvalidPauseReason = errors.New("pause reason must be a single word such as 'lunch' or 'meeting'")
+	ErrInvalidIdleDecision = errors.New("idle time can only be kept or discarded")
+	ErrPauseNotFound = errors.New("pause not found")
+	ErrNotIdlePause = errors.New("only an ended AFK pause can be kept or discarded")
+	ErrOverlappingSession = errors.New("overlaps existing session")
+	ErrSessionNotFound = errors.New("session not found")
+	ErrInvalidSessionTimes = errors.New("a session must end after it starts")
+	ErrPauseOutOfBounds = errors.New("pause lies outside its session")
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrCannotUndo = errors.New("cannot be undone")
 	ErrActiveSessionAlreadyActive = errors.New("⚠️active session is already active")
 )
@@ -0,0 +1,42 @@
+package db
+
+import "time"
+
+// SessionState selects sessions by whether they are still running.
+type SessionState int
+
+const (
+	AnySession SessionState = iota
+	ActiveSessions
+	CompletedSessions
+)
+
+// SortOrder orders sessions by their start time.
+type SortOrder int
+
+const (
+	OldestFirst SortOrder = iota
+	NewestFirst
+)
+
+// SessionFilter narrows down ListSessions. Zero values do not filter.
+type SessionFilter struct {
+	// From and To select sessions overlapping the half-open range [From, To)
+	From time.Time
+	To   time.Time
+	// Branch is a glob such as "feature/*", matched case-sensitively
+	Branch  string
+	Ticket  string
+	Client  string
+	Project string
+	// Repo matches either the repository ID or its top-level path
+	Repo  string
+	Tag   string
+	State SessionState
+	// Unsynced selects sessions without a recorded worklog or skip
+	Unsynced bool
+	Order    SortOrder
+	// Limit caps the number of sessions returned, Offset skips the first ones
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IdleDecision is what the user made of the idle time of an AFK pause after
+// coming back: away from the keyboard is not always away from work.
+type IdleDecision string
+
+const (
+	// IdleUndecided pauses count as paused until decided
+	IdleUndecided IdleDecision = ""
+	// IdleKept time counts as worked, under the pause's activity if it has one
+	IdleKept IdleDecision = "keep"
+	// IdleDiscarded time stays a pause
+	IdleDiscarded IdleDecision = "discard"
+)
+
+// ParseIdleDecision accepts keep, discard or nothing for undecided.
+func ParseIdleDecision(s string) (IdleDecision, error) {
+	switch d := IdleDecision(strings.ToLower(strings.TrimSpace(s))); d {
+	case IdleUndecided, IdleKept, IdleDiscarded:
+		return d, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidIdleDecision, s)
+}
+
+// AwaitsDecision reports whether p is an ended AFK, suspend or screen lock
+// pause nobody has kept or discarded yet.
+func (p Pause) AwaitsDecision() bool {
+	return p.Reason.IsAfk() && p.PauseEnd != nil && p.Decision == IdleUndecided
+}
+
+// IsKept reports whether the time of p counts as worked.
+func (p Pause) IsKept() bool {
+	return p.Decision == IdleKept
+}
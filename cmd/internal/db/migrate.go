@@ -0,0 +1,365 @@
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// Migration is a single numbered up-migration. Migrations are plain SQL files
+// named NNNN_description.sql and are applied in version order.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrationState reports whether a migration has been applied to a database.
+type MigrationState struct {
+	Migration
+	Applied bool
+	// AppliedAt is nil for a database from before versioned migrations, whose
+	// initial schema counts as applied without a date
+	AppliedAt *time.Time
+	// Unknown marks a migration applied by a newer binary, which knows
+	// nothing but its version and name
+	Unknown bool
+}
+
+// Migrations returns the migrations embedded in the binary, ordered by version.
+func Migrations() ([]Migration, error) {
+	return loadMigrations(embeddedMigrations, "migrations")
+}
+
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	seen := map[int]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %q: expected NNNN_description.sql", entry.Name())
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %q: invalid version %q", entry.Name(), prefix)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migration %q: version %d already used by %q", entry.Name(), version, other)
+		}
+		seen[version] = entry.Name()
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+			SQL:     string(content),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// MigrationStatus reports which of the embedded migrations are applied to the
+// database at dbPath and which are pending, followed by any migrations of a
+// newer binary. The database is opened read-only and a missing one is not
+// created.
+func MigrationStatus(dbPath string) ([]MigrationState, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		return migrationStates(migrations, nil), nil
+	}
+
+	conn, err := sql.Open("sqlite3", "file:"+(&url.URL{Path: dbPath}).EscapedPath()+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return migrationStatus(conn, migrations)
+}
+
+// Migrate applies all pending embedded migrations to the database at dbPath
+// and returns the ones that were applied.
+func Migrate(dbPath string) ([]Migration, error) {
+	conn, err := openSQLite(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	return runMigrations(conn, migrations)
+}
+
+// migrationStatus reads what is applied to conn without changing it. Without
+// a schema_version table nothing is applied, unless the database predates
+// versioned migrations and holds the initial schema.
+func migrationStatus(conn *sql.DB, migrations []Migration) ([]MigrationState, error) {
+	versioned, err := tableExists(conn, "schema_version")
+	if err != nil {
+		return nil, err
+	}
+
+	if !versioned {
+		legacy, err := tableExists(conn, "sessions")
+		if err != nil {
+			return nil, err
+		}
+		if legacy {
+			return migrationStates(migrations, map[int]appliedMigration{1: {name: "initial_schema"}}), nil
+		}
+		return migrationStates(migrations, nil), nil
+	}
+
+	applied, err := appliedMigrations(conn)
+	if err != nil {
+		return nil, err
+	}
+	return migrationStates(migrations, applied), nil
+}
+
+func migrationStates(migrations []Migration, applied map[int]appliedMigration) []MigrationState {
+	states := make([]MigrationState, 0, len(migrations))
+	known := map[int]bool{}
+	for _, m := range migrations {
+		known[m.Version] = true
+		state := MigrationState{Migration: m}
+		if a, ok := applied[m.Version]; ok {
+			state.Applied = true
+			state.AppliedAt = a.at
+		}
+		states = append(states, state)
+	}
+
+	var unknown []MigrationState
+	for version, a := range applied {
+		if !known[version] {
+			unknown = append(unknown, MigrationState{
+				Migration: Migration{Version: version, Name: a.name},
+				Applied:   true,
+				AppliedAt: a.at,
+				Unknown:   true,
+			})
+		}
+	}
+	sort.Slice(unknown, func(i, j int) bool {
+		return unknown[i].Version < unknown[j].Version
+	})
+
+	return append(states, unknown...)
+}
+
+// runMigrations applies every migration newer than the database's current
+// version, each inside its own transaction. A database whose version is newer
+// than the newest known migration was written by a newer binary and is refused.
+func runMigrations(conn *sql.DB, migrations []Migration) ([]Migration, error) {
+	if err := ensureSchemaVersionTable(conn); err != nil {
+		return nil, err
+	}
+
+	current, err := schemaVersion(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := 0
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+	if current > latest {
+		return nil, fmt.Errorf("%w: database is at version %d, this binary knows up to %d", ErrDatabaseVersionTooNew, current, latest)
+	}
+
+	var applied []Migration
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+
+		if err := applyMigration(conn, m); err != nil {
+			return applied, fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+func applyMigration(conn *sql.DB, m Migration) error {
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.SQL); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`, m.Version, m.Name, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ensureSchemaVersionTable creates the schema_version table. Databases created
+// before versioned migrations already hold the initial schema, so they are
+// adopted at version 1 instead of replaying it.
+func ensureSchemaVersionTable(conn *sql.DB) error {
+	exists, err := tableExists(conn, "schema_version")
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	legacy, err := tableExists(conn, "sessions")
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		CREATE TABLE schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)`)
+	if err != nil {
+		return err
+	}
+
+	if legacy {
+		if err := addColumnIfMissing(tx, "pauses", "is_afk", "BOOLEAN DEFAULT 0"); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (1, 'initial_schema', ?)`, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func schemaVersion(conn *sql.DB) (int, error) {
+	var version sql.NullInt64
+	if err := conn.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// appliedMigration is a row of schema_version.
+type appliedMigration struct {
+	name string
+	at   *time.Time
+}
+
+func appliedMigrations(conn *sql.DB) (map[int]appliedMigration, error) {
+	rows, err := conn.Query(`SELECT version, name, applied_at FROM schema_version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]appliedMigration{}
+	for rows.Next() {
+		var version int
+		var name string
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &name, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedMigration{name: name, at: &appliedAt}
+	}
+
+	return applied, rows.Err()
+}
+
+func tableExists(conn *sql.DB, table string) (bool, error) {
+	var count int
+	err := conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func addColumnIfMissing(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    bool
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
@@ -0,0 +1,233 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func openTestConn(t *testing.T) *sql.DB {
+	t.Helper()
+
+	conn, err := openSQLite(filepath.Join(t.TempDir(), "lofi-tracker.db"))
+	if err != nil {
+		t.Fatalf("expected no error opening database, got %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestLoadMigrations_ShouldOrderByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0010_later.sql":  {Data: []byte(`SELECT 1;`)},
+		"migrations/0002_second.sql": {Data: []byte(`SELECT 1;`)},
+		"migrations/0001_first.sql":  {Data: []byte(`SELECT 1;`)},
+	}
+
+	migrations, err := loadMigrations(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var versions []int
+	for _, m := range migrations {
+		versions = append(versions, m.Version)
+	}
+	if len(versions) != 3 || versions[0] != 1 || versions[1] != 2 || versions[2] != 10 {
+		t.Errorf("expected versions [1 2 10], got %v", versions)
+	}
+}
+
+func TestLoadMigrations_WhenVersionIsDuplicated_ShouldFail(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_first.sql": {Data: []byte(`SELECT 1;`)},
+		"migrations/0001_other.sql": {Data: []byte(`SELECT 1;`)},
+	}
+
+	if _, err := loadMigrations(fsys, "migrations"); err == nil {
+		t.Errorf("expected an error for duplicate versions, got nil")
+	}
+}
+
+func TestRunMigrations_ShouldApplyOnlyPendingMigrations(t *testing.T) {
+	conn := openTestConn(t)
+
+	migrations := []Migration{
+		{Version: 1, Name: "create", SQL: `CREATE TABLE things (id INTEGER PRIMARY KEY);`},
+	}
+	applied, err := runMigrations(conn, migrations)
+	if err != nil || len(applied) != 1 {
+		t.Fatalf("expected one applied migration, got %d (err %v)", len(applied), err)
+	}
+
+	migrations = append(migrations, Migration{Version: 2, Name: "alter", SQL: `ALTER TABLE things ADD COLUMN name TEXT;`})
+	applied, err = runMigrations(conn, migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 2 {
+		t.Errorf("expected only migration 2 to be applied, got %+v", applied)
+	}
+
+	version, err := schemaVersion(conn)
+	if err != nil || version != 2 {
+		t.Errorf("expected schema version 2, got %d (err %v)", version, err)
+	}
+}
+
+func TestRunMigrations_WhenStepFails_ShouldRollBackThatStep(t *testing.T) {
+	conn := openTestConn(t)
+
+	migrations := []Migration{
+		{Version: 1, Name: "create", SQL: `CREATE TABLE things (id INTEGER PRIMARY KEY);`},
+		{Version: 2, Name: "broken", SQL: `CREATE TABLE others (id INTEGER); NOT VALID SQL;`},
+	}
+	if _, err := runMigrations(conn, migrations); err == nil {
+		t.Fatalf("expected the broken migration to fail")
+	}
+
+	version, err := schemaVersion(conn)
+	if err != nil || version != 1 {
+		t.Errorf("expected schema version 1, got %d (err %v)", version, err)
+	}
+
+	exists, err := tableExists(conn, "others")
+	if err != nil || exists {
+		t.Errorf("expected the failed step to be rolled back, table exists=%v (err %v)", exists, err)
+	}
+}
+
+func TestRunMigrations_WhenDatabaseIsNewer_ShouldRefuse(t *testing.T) {
+	conn := openTestConn(t)
+
+	migrations := []Migration{
+		{Version: 1, Name: "create", SQL: `CREATE TABLE things (id INTEGER PRIMARY KEY);`},
+		{Version: 2, Name: "alter", SQL: `ALTER TABLE things ADD COLUMN name TEXT;`},
+	}
+	if _, err := runMigrations(conn, migrations); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err := runMigrations(conn, migrations[:1])
+	if !errors.Is(err, ErrDatabaseVersionTooNew) {
+		t.Errorf("expected ErrDatabaseVersionTooNew, got %v", err)
+	}
+}
+
+func TestRunMigrations_WhenDatabasePredatesMigrations_ShouldAdoptIt(t *testing.T) {
+	conn := openTestConn(t)
+
+	_, err := conn.Exec(`
+		CREATE TABLE sessions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			branch TEXT NOT NULL,
+			start_time TIMESTAMP NOT NULL,
+			end_time TIMESTAMP,
+			is_paused BOOLEAN DEFAULT 0,
+			is_afk BOOLEAN default 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE pauses (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id INTEGER NOT NULL,
+			pause_start TIMESTAMP NOT NULL,
+			pause_end TIMESTAMP,
+			FOREIGN KEY(session_id) REFERENCES sessions(id)
+		);
+		INSERT INTO sessions (branch, start_time) VALUES ('main', CURRENT_TIMESTAMP);`)
+	if err != nil {
+		t.Fatalf("expected no error creating legacy schema, got %v", err)
+	}
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("expected no error loading migrations, got %v", err)
+	}
+	if _, err := runMigrations(conn, migrations); err != nil {
+		t.Fatalf("expected legacy database to migrate, got %v", err)
+	}
+
+	var count int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM sessions`).Scan(&count); err != nil || count != 1 {
+		t.Errorf("expected the legacy session to survive, got %d (err %v)", count, err)
+	}
+
+	if _, err := conn.Exec(`INSERT INTO pauses (session_id, pause_start, is_afk) VALUES (1, CURRENT_TIMESTAMP, 1)`); err != nil {
+		t.Errorf("expected pauses.is_afk to exist after adoption, got %v", err)
+	}
+}
+
+func TestMigrationStatus_WhenDatabaseIsMissing_ShouldNotCreateIt(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "data", "lofi-tracker.db")
+
+	states, err := MigrationStatus(dbPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, state := range states {
+		if state.Applied {
+			t.Errorf("expected %04d to be pending, got applied", state.Version)
+		}
+	}
+	if _, err := os.Stat(filepath.Dir(dbPath)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the database directory not to be created, got %v", err)
+	}
+}
+
+func TestMigrationStatus_WhenDatabasePredatesMigrations_ShouldReportInitialSchemaWithoutAdoptingIt(t *testing.T) {
+	conn := openTestConn(t)
+
+	if _, err := conn.Exec(`CREATE TABLE sessions (id INTEGER PRIMARY KEY AUTOINCREMENT, branch TEXT NOT NULL);`); err != nil {
+		t.Fatalf("expected no error creating legacy schema, got %v", err)
+	}
+
+	migrations := []Migration{
+		{Version: 1, Name: "initial_schema"},
+		{Version: 2, Name: "alter"},
+	}
+	states, err := migrationStatus(conn, migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(states) != 2 || !states[0].Applied || states[0].AppliedAt != nil || states[1].Applied {
+		t.Errorf("expected only the initial schema applied without a date, got %+v", states)
+	}
+
+	exists, err := tableExists(conn, "schema_version")
+	if err != nil || exists {
+		t.Errorf("expected schema_version not to be created, table exists=%v (err %v)", exists, err)
+	}
+}
+
+func TestMigrationStatus_WhenDatabaseIsNewer_ShouldListUnknownMigrations(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "lofi-tracker.db")
+	conn, err := openSQLite(dbPath)
+	if err != nil {
+		t.Fatalf("expected no error opening database, got %v", err)
+	}
+	defer conn.Close()
+
+	migrations := []Migration{
+		{Version: 1, Name: "create", SQL: `CREATE TABLE things (id INTEGER PRIMARY KEY);`},
+		{Version: 2, Name: "alter", SQL: `ALTER TABLE things ADD COLUMN name TEXT;`},
+	}
+	if _, err := runMigrations(conn, migrations); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	states, err := migrationStatus(conn, migrations[:1])
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(states) != 2 || states[0].Unknown || !states[1].Unknown || states[1].Version != 2 || states[1].Name != "alter" {
+		t.Errorf("expected migration 0002 alter listed as unknown, got %+v", states)
+	}
+}
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    branch TEXT NOT NULL,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP,
+    is_paused BOOLEAN DEFAULT 0,
+    is_afk BOOLEAN DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS pauses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL,
+    pause_start TIMESTAMP NOT NULL,
+    pause_end TIMESTAMP,
+    is_afk BOOLEAN DEFAULT 0,
+    FOREIGN KEY(session_id) REFERENCES sessions(id)
+);
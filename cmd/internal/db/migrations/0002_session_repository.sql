@@ -0,0 +1,6 @@
+ALTER TABLE sessions ADD COLUMN repo_path TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN repo_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN start_commit TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN end_commit TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_sessions_repo_id ON sessions(repo_id);
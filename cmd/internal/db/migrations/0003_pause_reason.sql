@@ -0,0 +1,4 @@
+ALTER TABLE pauses ADD COLUMN reason TEXT NOT NULL DEFAULT 'manual';
+ALTER TABLE pauses ADD COLUMN note TEXT NOT NULL DEFAULT '';
+
+UPDATE pauses SET reason = 'afk' WHERE is_afk = 1;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS session_tags (
+    session_id INTEGER NOT NULL,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (session_id, tag),
+    FOREIGN KEY(session_id) REFERENCES sessions(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_tags_tag ON session_tags(tag);
+CREATE INDEX IF NOT EXISTS idx_sessions_start_time ON sessions(start_time);
+CREATE INDEX IF NOT EXISTS idx_sessions_end_time ON sessions(end_time);
+CREATE INDEX IF NOT EXISTS idx_sessions_branch ON sessions(branch);
+CREATE INDEX IF NOT EXISTS idx_pauses_session_id ON pauses(session_id);
@@ -0,0 +1,3 @@
+ALTER TABLE sessions ADD COLUMN ticket TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_sessions_ticket ON sessions(ticket);
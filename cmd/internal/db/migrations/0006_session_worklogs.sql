@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS session_worklogs (
+    session_id INTEGER PRIMARY KEY,
+    issue_key TEXT NOT NULL,
+    worklog_id TEXT NOT NULL,
+    seconds INTEGER NOT NULL,
+    synced_at TIMESTAMP NOT NULL,
+    FOREIGN KEY(session_id) REFERENCES sessions(id)
+);
@@ -0,0 +1 @@
+ALTER TABLE sessions ADD COLUMN origin TEXT NOT NULL DEFAULT 'tracked';
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS session_audit (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    before TEXT,
+    after TEXT,
+    changed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_audit_session_id ON session_audit(session_id);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS operations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL,
+    session_id INTEGER NOT NULL,
+    pause_id INTEGER,
+    next_session_id INTEGER,
+    at TIMESTAMP NOT NULL,
+    undone_at TIMESTAMP
+);
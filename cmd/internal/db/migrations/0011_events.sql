@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,
+    session_id INTEGER NOT NULL,
+    pause_id INTEGER,
+    at TIMESTAMP NOT NULL,
+    data TEXT NOT NULL DEFAULT '{}',
+    recorded_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_session_id ON events(session_id);
+
+-- Seed the log with the history recorded so far, so it can be rebuilt from
+-- events alone
+INSERT INTO events (type, session_id, at, data, recorded_at)
+SELECT 'session_started', id, start_time, json_object(
+        'branch', branch, 'ticket', ticket, 'client', client, 'project', project,
+        'repo_path', repo_path, 'repo_id', repo_id, 'start_commit', start_commit, 'origin', origin,
+        'tags', json((SELECT json_group_array(tag) FROM session_tags WHERE session_id = sessions.id))),
+    COALESCE(created_at, CURRENT_TIMESTAMP)
+FROM sessions
+ORDER BY start_time, id;
+
+INSERT INTO events (type, session_id, pause_id, at, data, recorded_at)
+SELECT 'paused', session_id, id, pause_start, json_object('reason', reason, 'note', note, 'is_afk', json(CASE WHEN is_afk THEN 'true' ELSE 'false' END)), CURRENT_TIMESTAMP
+FROM pauses
+ORDER BY pause_start, id;
+
+INSERT INTO events (type, session_id, pause_id, at, recorded_at)
+SELECT 'resumed', session_id, id, pause_end, CURRENT_TIMESTAMP
+FROM pauses
+WHERE pause_end IS NOT NULL
+ORDER BY pause_end, id;
+
+INSERT INTO events (type, session_id, at, data, recorded_at)
+SELECT 'completed', id, end_time, json_object('end_commit', end_commit), COALESCE(updated_at, CURRENT_TIMESTAMP)
+FROM sessions
+WHERE end_time IS NOT NULL
+ORDER BY end_time, id;
@@ -0,0 +1,12 @@
+ALTER TABLE pauses ADD COLUMN decision TEXT NOT NULL DEFAULT '';
+ALTER TABLE pauses ADD COLUMN activity TEXT NOT NULL DEFAULT '';
+
+-- AFK pauses from before the prompt stay pauses, rather than all asking at
+-- once
+UPDATE pauses SET decision = 'discard' WHERE reason IN ('afk', 'suspend', 'screen-lock') AND pause_end IS NOT NULL;
+
+INSERT INTO events (type, session_id, pause_id, at, data, recorded_at)
+SELECT 'idle_decided', session_id, id, pause_end, json_object('decision', 'discard', 'activity', ''), CURRENT_TIMESTAMP
+FROM pauses
+WHERE reason IN ('afk', 'suspend', 'screen-lock') AND pause_end IS NOT NULL
+ORDER BY pause_end, id;
@@ -0,0 +1,3 @@
+-- Sessions the sync decided not to log are recorded too, with the reason and
+-- no worklog, so they are not offered again on every run
+ALTER TABLE session_worklogs ADD COLUMN skipped TEXT NOT NULL DEFAULT '';
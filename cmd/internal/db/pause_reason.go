@@ -0,0 +1,47 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PauseReason says why a session was paused. The known reasons below are used
+// by the CLI and the AFK watcher, but any single lowercase word such as
+// "lunch" or "errand" is accepted so people can label their own breaks.
+type PauseReason string
+
+const (
+	PauseReasonManual     PauseReason = "manual"
+	PauseReasonAfk        PauseReason = "afk"
+	PauseReasonSuspend    PauseReason = "suspend"
+	PauseReasonScreenLock PauseReason = "screen-lock"
+	PauseReasonMeeting    PauseReason = "meeting"
+	PauseReasonLunch      PauseReason = "lunch"
+)
+
+// IsAfk reports whether the pause was detected automatically because the
+// user was away, as opposed to being requested by the user.
+func (r PauseReason) IsAfk() bool {
+	switch r {
+	case PauseReasonAfk, PauseReasonSuspend, PauseReasonScreenLock:
+		return true
+	}
+	return false
+}
+
+// ParsePauseReason normalizes a user supplied reason. An empty reason means
+// a plain manual pause.
+func ParsePauseReason(s string) (PauseReason, error) {
+	reason := strings.ToLower(strings.TrimSpace(s))
+	if reason == "" {
+		return PauseReasonManual, nil
+	}
+
+	for _, r := range reason {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return "", fmt.Errorf("%w: %q", ErrInvalidPauseReason, s)
+		}
+	}
+
+	return PauseReason(reason), nil
+}
@@ -16,18 +16,17 @@ type sqliteDB struct {
 }
 
 func NewSQLiteDB(dbPath string) (DB, error) {
-	if err := os.MkdirAll(filepath.Dir(dbPath), 0700); err != nil {
-		return nil, ErrFailedToCreateDirectoryForDatabase
-	}
-
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := openSQLite(dbPath)
 	if err != nil {
-		fmt.Printf("❌ Failed to open database: %v\n", err)
-		return nil, ErrFailedToOpenDatabase
+		return nil, err
 	}
 
 	sdb := &sqliteDB{db: db}
 	if err := sdb.migrate(); err != nil {
+		db.Close()
+		if errors.Is(err, ErrDatabaseVersionTooNew) {
+			return nil, err
+		}
 		fmt.Printf("❌ Failed to migrate database: %v\n", err)
 		return nil, ErrFailedToMigrateDatabase
 	}
@@ -36,21 +35,154 @@ func NewSQLiteDB(dbPath string) (DB, error) {
 }
 
 // CompleteSession implements DB.
-func (s *sqliteDB) CompleteSession(sessionID int64, endTime time.Time) error {
-	_, err := s.db.Exec(`UPDATE sessions SET end_time = ?, is_paused = 0, is_afk = 0 WHERE id = ?`, endTime, sessionID)
+func (s *sqliteDB) CompleteSession(sessionID int64, endTime time.Time, endCommit string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	pauseID, err := completeSession(tx, sessionID, endTime, endCommit)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	err = recordOperation(tx, Operation{Kind: OpComplete, SessionID: sessionID, PauseID: pauseID, At: endTime})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // CreateSession implements DB.
-func (s *sqliteDB) CreateSession(branch string, startTime time.Time) (int64, error) {
-	res, err := s.db.Exec(`
-		INSERT INTO sessions (branch, start_time, is_paused, created_at, updated_at)
-		VALUES (?, ?, 0, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-		`, branch, startTime)
+func (s *sqliteDB) CreateSession(session Session) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	id, err := insertSession(tx, session)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := recordOperation(tx, Operation{Kind: OpStart, SessionID: id, At: session.StartTime}); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// AddSession implements DB.
+func (s *sqliteDB) AddSession(session Session) (int64, error) {
+	if session.Endtime == nil || !session.Endtime.After(session.StartTime) {
+		return 0, ErrInvalidSessionTimes
+	}
+	session = inUTC(session)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := checkOverlap(tx, session); err != nil {
+		return 0, err
+	}
+
+	id, err := insertSession(tx, session)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// SwitchSession implements DB.
+func (s *sqliteDB) SwitchSession(sessionID int64, at time.Time, endCommit string, next Session) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	pauseID, err := completeSession(tx, sessionID, at, endCommit)
+	if err != nil {
+		return 0, err
+	}
+
+	next.StartTime = at
+	id, err := insertSession(tx, next)
+	if err != nil {
+		return 0, err
+	}
+
+	err = recordOperation(tx, Operation{Kind: OpSwitch, SessionID: sessionID, PauseID: pauseID, NextSessionID: id, At: at})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// completeSession ends a session and returns the ID of the pause it closed,
+// if it was paused.
+func completeSession(tx *sql.Tx, sessionID int64, endTime time.Time, endCommit string) (int64, error) {
+	var pauseID int64
+	err := tx.QueryRow(`SELECT id FROM pauses WHERE session_id = ? AND pause_end IS NULL ORDER BY pause_start DESC LIMIT 1`, sessionID).Scan(&pauseID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	// A session completed while paused closes its open pause at the same moment
+	_, err = tx.Exec(`UPDATE pauses SET pause_end = ? WHERE session_id = ? AND pause_end IS NULL`, endTime, sessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE sessions SET end_time = ?, end_commit = ?, is_paused = 0, is_afk = 0, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND end_time IS NULL
+		`, endTime, endCommit, sessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return 0, ErrNoActiveSession
+	}
+
+	err = recordEventData(tx, event{Type: eventCompleted, SessionID: sessionID, At: endTime}, completedData{EndCommit: endCommit})
+	if err != nil {
+		return 0, err
+	}
+
+	return pauseID, nil
+}
+
+func insertSession(tx *sql.Tx, session Session) (int64, error) {
+	if session.Origin == "" {
+		session.Origin = OriginTracked
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO sessions (branch, ticket, client, project, repo_path, repo_id, start_commit, end_commit, start_time, end_time, origin, is_paused, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		`, session.Branch, session.Ticket, session.Client, session.Project, session.RepoPath, session.RepoID, session.StartCommit, session.EndCommit, session.StartTime, session.Endtime, session.Origin)
 	if err != nil {
 		return 0, err
 	}
@@ -60,25 +192,47 @@ func (s *sqliteDB) CreateSession(branch string, startTime time.Time) (int64, err
 		return 0, err
 	}
 
+	for _, tag := range session.Tags {
+		_, err = tx.Exec(`INSERT OR IGNORE INTO session_tags (session_id, tag) VALUES (?, ?)`, id, tag)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = recordEventData(tx, event{Type: eventSessionStarted, SessionID: id, At: session.StartTime}, startedData{
+		Branch:      session.Branch,
+		Ticket:      session.Ticket,
+		Client:      session.Client,
+		Project:     session.Project,
+		RepoPath:    session.RepoPath,
+		RepoID:      session.RepoID,
+		StartCommit: session.StartCommit,
+		Origin:      session.Origin,
+		Tags:        session.Tags,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if session.Endtime != nil {
+		err = recordEventData(tx, event{Type: eventCompleted, SessionID: id, At: *session.Endtime}, completedData{EndCommit: session.EndCommit})
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	return id, nil
 }
 
 // GetActiveSession implements DB.
 func (s *sqliteDB) GetActiveSession() (*Session, error) {
-	var sessionID int64
-	var branch string
-	var startTime time.Time
-	var endTime *time.Time
-	var isPaused bool
-	var isAfk bool
-
-	err := s.db.QueryRow(`
-		SELECT id, branch, start_time, end_time, is_paused, is_afk
+	session, err := scanSession(s.db.QueryRow(`
+		SELECT ` + sessionColumns + `
 		FROM sessions
 		WHERE end_time IS NULL
 		ORDER BY start_time DESC
 		LIMIT 1
-		`).Scan(&sessionID, &branch, &startTime, &endTime, &isPaused, &isAfk)
+		`))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNoActiveSession
@@ -86,32 +240,22 @@ func (s *sqliteDB) GetActiveSession() (*Session, error) {
 		return nil, err
 	}
 
-	if endTime != nil {
-		return &Session{
-			ID:        sessionID,
-			Branch:    branch,
-			StartTime: startTime,
-			Endtime:   endTime,
-			IsPaused:  isPaused,
-			IsAfk:     isAfk,
-		}, nil
-	}
-
-	return &Session{
-		ID:        sessionID,
-		Branch:    branch,
-		StartTime: startTime,
-		IsPaused:  isPaused,
-		IsAfk:     isAfk,
-	}, nil
+	return &session, nil
 }
 
 // PauseSession implements DB.
-func (s *sqliteDB) PauseSession(sessionID int64, pauseStart time.Time, isAfk bool) (int64, error) {
-	res, err := s.db.Exec(`
-		INSERT INTO pauses (pause_start, pause_end, session_id)
-		VALUES (?, NULL, ?)
-		`, pauseStart, sessionID)
+func (s *sqliteDB) PauseSession(sessionID int64, pauseStart time.Time, reason PauseReason, note string) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	isAfk := reason.IsAfk()
+	res, err := tx.Exec(`
+		INSERT INTO pauses (pause_start, pause_end, session_id, is_afk, reason, note)
+		VALUES (?, NULL, ?, ?, ?, ?)
+		`, pauseStart, sessionID, isAfk, reason, note)
 	if err != nil {
 		return 0, err
 	}
@@ -121,19 +265,46 @@ func (s *sqliteDB) PauseSession(sessionID int64, pauseStart time.Time, isAfk boo
 		return 0, err
 	}
 
-	_, err = s.db.Exec(`UPDATE sessions SET is_paused = 1, is_afk = 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, sessionID)
+	_, err = tx.Exec(`UPDATE sessions SET is_paused = 1, is_afk = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, isAfk, sessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = recordEventData(tx, event{Type: eventPaused, SessionID: sessionID, PauseID: id, At: pauseStart}, pausedData{Reason: reason, Note: note, IsAfk: isAfk})
 	if err != nil {
 		return 0, err
 	}
 
+	if err := recordOperation(tx, Operation{Kind: OpPause, SessionID: sessionID, PauseID: id, At: pauseStart}); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
 	return id, nil
 }
 
+// RecordWorklog implements DB.
+func (s *sqliteDB) RecordWorklog(worklog Worklog) error {
+	_, err := s.db.Exec(`
+		INSERT INTO session_worklogs (session_id, issue_key, worklog_id, seconds, synced_at, skipped)
+		VALUES (?, ?, ?, ?, ?, ?)
+		`, worklog.SessionID, worklog.IssueKey, worklog.WorklogID, worklog.Seconds, worklog.SyncedAt.UTC(), worklog.Skipped)
+	return err
+}
+
 // ResumeSession implements DB.
 func (s *sqliteDB) ResumeSession(sessionID int64, pauseEnd time.Time) error {
-	var pauseID int64
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	err := s.db.QueryRow(`
+	var pauseID int64
+	err = tx.QueryRow(`
 		SELECT id FROM pauses 
 		WHERE session_id = ? AND pause_end IS NULL
 		ORDER BY pause_start DESC
@@ -146,17 +317,115 @@ func (s *sqliteDB) ResumeSession(sessionID int64, pauseEnd time.Time) error {
 		return err
 	}
 
-	_, err = s.db.Exec(`UPDATE pauses SET pause_end = ? WHERE id = ?`, pauseEnd, pauseID)
+	_, err = tx.Exec(`UPDATE pauses SET pause_end = ? WHERE id = ?`, pauseEnd, pauseID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE sessions SET is_paused = 0, is_afk = 0 WHERE id = ?`, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if err := recordEvent(tx, event{Type: eventResumed, SessionID: sessionID, PauseID: pauseID, At: pauseEnd}); err != nil {
+		return err
+	}
+
+	if err := recordOperation(tx, Operation{Kind: OpResume, SessionID: sessionID, PauseID: pauseID, At: pauseEnd}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DecideIdle implements DB.
+func (s *sqliteDB) DecideIdle(pauseID int64, at time.Time, decision IdleDecision, activity string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var sessionID int64
+	var reason PauseReason
+	var pauseEnd *time.Time
+	err = tx.QueryRow(`SELECT session_id, reason, pause_end FROM pauses WHERE id = ?`, pauseID).Scan(&sessionID, &reason, &pauseEnd)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPauseNotFound
+		}
+		return err
+	}
+	if !reason.IsAfk() || pauseEnd == nil {
+		return ErrNotIdlePause
+	}
+	if decision != IdleKept {
+		activity = ""
+	}
+
+	_, err = tx.Exec(`UPDATE pauses SET decision = ?, activity = ? WHERE id = ?`, decision, activity, pauseID)
 	if err != nil {
 		return err
 	}
 
-	_, err = s.db.Exec(`UPDATE sessions SET is_paused = 0, is_afk = 0 WHERE id = ?`, sessionID)
+	err = recordEventData(tx, event{Type: eventIdleDecided, SessionID: sessionID, PauseID: pauseID, At: at.UTC()}, idleDecidedData{Decision: decision, Activity: activity})
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return tx.Commit()
+}
+
+// ListPauses implements DB.
+func (s *sqliteDB) ListPauses(sessionID int64) ([]Pause, error) {
+	rows, err := s.db.Query(`
+		SELECT `+pauseColumns+`
+		FROM pauses
+		WHERE session_id = ?
+		ORDER BY pause_start ASC
+		`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pauses []Pause
+	for rows.Next() {
+		p, err := scanPause(rows)
+		if err != nil {
+			return nil, err
+		}
+		pauses = append(pauses, p)
+	}
+
+	return pauses, rows.Err()
+}
+
+// ListPendingIdle implements DB.
+func (s *sqliteDB) ListPendingIdle(since time.Time) ([]Pause, error) {
+	rows, err := s.db.Query(`
+		SELECT `+pauseColumns+`
+		FROM pauses
+		WHERE pause_end > ? AND decision = ''
+		ORDER BY pause_end ASC, id ASC
+		`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pauses []Pause
+	for rows.Next() {
+		p, err := scanPause(rows)
+		if err != nil {
+			return nil, err
+		}
+		if p.AwaitsDecision() && p.PauseEnd.After(p.PauseStart) {
+			pauses = append(pauses, p)
+		}
+	}
+
+	return pauses, rows.Err()
 }
 
 func (s *sqliteDB) Close() error {
@@ -164,26 +433,25 @@ func (s *sqliteDB) Close() error {
 }
 
 func (s *sqliteDB) migrate() error {
-	schema := `
-    CREATE TABLE IF NOT EXISTS sessions (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        branch TEXT NOT NULL,
-        start_time TIMESTAMP NOT NULL,
-        end_time TIMESTAMP,
-        is_paused BOOLEAN DEFAULT 0,
-		is_afk BOOLEAN default 0, 
-        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-    );
-
-    CREATE TABLE IF NOT EXISTS pauses (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        session_id INTEGER NOT NULL,
-        pause_start TIMESTAMP NOT NULL,
-        pause_end TIMESTAMP,
-        FOREIGN KEY(session_id) REFERENCES sessions(id)
-    );
-    `
-	_, err := s.db.Exec(schema)
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	_, err = runMigrations(s.db, migrations)
 	return err
 }
+
+func openSQLite(dbPath string) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0700); err != nil {
+		return nil, ErrFailedToCreateDirectoryForDatabase
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		fmt.Printf("❌ Failed to open database: %v\n", err)
+		return nil, ErrFailedToOpenDatabase
+	}
+
+	return db, nil
+}
@@ -0,0 +1,352 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// GetSession implements DB.
+func (s *sqliteDB) GetSession(sessionID int64) (*Session, error) {
+	session, err := scanSession(s.db.QueryRow(`SELECT `+sessionColumns+` FROM sessions WHERE id = ?`, sessionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	sessions := []Session{session}
+	if err := s.loadSessionDetails(sessions); err != nil {
+		return nil, err
+	}
+	return &sessions[0], nil
+}
+
+// UpdateSession implements DB.
+func (s *sqliteDB) UpdateSession(session Session) error {
+	if err := ValidateSession(session); err != nil {
+		return err
+	}
+	session = inUTC(session)
+
+	before, err := s.GetSession(session.ID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkOverlap(tx, session); err != nil {
+		return err
+	}
+
+	var paused, afk bool
+	if n := len(session.Pauses); n > 0 && session.Pauses[n-1].PauseEnd == nil {
+		paused = true
+		afk = session.Pauses[n-1].Reason.IsAfk()
+	}
+
+	_, err = tx.Exec(`
+		UPDATE sessions SET branch = ?, ticket = ?, start_time = ?, end_time = ?, is_paused = ?, is_afk = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+		`, session.Branch, session.Ticket, session.StartTime, session.Endtime, paused, afk, session.ID)
+	if err != nil {
+		return err
+	}
+
+	// A sync that skipped the session gets to look at it again
+	if _, err := tx.Exec(`DELETE FROM session_worklogs WHERE session_id = ? AND skipped != ''`, session.ID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM session_tags WHERE session_id = ?`, session.ID); err != nil {
+		return err
+	}
+	for _, tag := range session.Tags {
+		_, err = tx.Exec(`INSERT OR IGNORE INTO session_tags (session_id, tag) VALUES (?, ?)`, session.ID, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Pauses are replaced wholesale, keeping the IDs of those that stay
+	if _, err := tx.Exec(`DELETE FROM pauses WHERE session_id = ?`, session.ID); err != nil {
+		return err
+	}
+	for _, p := range session.Pauses {
+		var id any
+		if p.ID != 0 {
+			id = p.ID
+		}
+		if p.Reason == "" {
+			p.Reason = PauseReasonManual
+		}
+		_, err = tx.Exec(`
+			INSERT INTO pauses (id, session_id, pause_start, pause_end, is_afk, reason, note, decision, activity)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, id, session.ID, p.PauseStart, p.PauseEnd, p.Reason.IsAfk(), p.Reason, p.Note, p.Decision, p.Activity)
+		if err != nil {
+			return err
+		}
+	}
+
+	after, err := getSession(tx, session.ID)
+	if err != nil {
+		return err
+	}
+	if err := recordAudit(tx, session.ID, AuditEdit, before, after); err != nil {
+		return err
+	}
+	if err := recordEventData(tx, event{Type: eventEdited, SessionID: session.ID, At: time.Now().UTC()}, after); err != nil {
+		return err
+	}
+	if err := forgetOperations(tx, session.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteSession implements DB.
+func (s *sqliteDB) DeleteSession(sessionID int64) error {
+	before, err := s.GetSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteSession(tx, sessionID); err != nil {
+		return err
+	}
+
+	if err := recordAudit(tx, sessionID, AuditDelete, before, nil); err != nil {
+		return err
+	}
+	if err := forgetOperations(tx, sessionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// deleteSession removes a session with everything recorded about it.
+func deleteSession(tx *sql.Tx, sessionID int64) error {
+	if _, err := tx.Exec(`DELETE FROM session_worklogs WHERE session_id = ?`, sessionID); err != nil {
+		return err
+	}
+	if err := removeSession(tx, sessionID); err != nil {
+		return err
+	}
+	return recordEvent(tx, event{Type: eventDeleted, SessionID: sessionID, At: time.Now().UTC()})
+}
+
+// ListAudit implements DB.
+func (s *sqliteDB) ListAudit(sessionID int64) ([]AuditEntry, error) {
+	query := `SELECT id, session_id, action, before, after, changed_at FROM session_audit`
+	var args []any
+	if sessionID != 0 {
+		query += ` WHERE session_id = ?`
+		args = append(args, sessionID)
+	}
+	query += ` ORDER BY id ASC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []AuditEntry
+	err = forEachRow(rows, func(rows *sql.Rows) error {
+		var e AuditEntry
+		var before, after sql.NullString
+		if err := rows.Scan(&e.ID, &e.SessionID, &e.Action, &before, &after, &e.ChangedAt); err != nil {
+			return err
+		}
+		if e.Before, err = decodeSnapshot(before); err != nil {
+			return err
+		}
+		if e.After, err = decodeSnapshot(after); err != nil {
+			return err
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
+}
+
+// ValidateSession checks that a session ends after it starts and that its
+// pauses lie inside it without overlapping each other. Only the last pause of
+// a session that is still running may be open.
+func ValidateSession(session Session) error {
+	if session.Endtime != nil && !session.Endtime.After(session.StartTime) {
+		return ErrInvalidSessionTimes
+	}
+
+	pauses := append([]Pause(nil), session.Pauses...)
+	sort.Slice(pauses, func(i, j int) bool {
+		return pauses[i].PauseStart.Before(pauses[j].PauseStart)
+	})
+
+	for i, p := range pauses {
+		if p.PauseStart.Before(session.StartTime) {
+			return fmt.Errorf("%w: pause at %s starts before the session", ErrPauseOutOfBounds, p.PauseStart.Format(time.DateTime))
+		}
+		if i > 0 && pauses[i-1].PauseEnd != nil && p.PauseStart.Before(*pauses[i-1].PauseEnd) {
+			return fmt.Errorf("%w: pause at %s overlaps the pause before it", ErrPauseOutOfBounds, p.PauseStart.Format(time.DateTime))
+		}
+
+		if p.PauseEnd == nil {
+			if session.Endtime != nil || i < len(pauses)-1 {
+				return fmt.Errorf("%w: only the last pause of a running session may be open", ErrPauseOutOfBounds)
+			}
+			continue
+		}
+		if !p.PauseEnd.After(p.PauseStart) {
+			return fmt.Errorf("%w: pause at %s must end after it starts", ErrPauseOutOfBounds, p.PauseStart.Format(time.DateTime))
+		}
+		if session.Endtime != nil && p.PauseEnd.After(*session.Endtime) {
+			return fmt.Errorf("%w: pause at %s ends after the session", ErrPauseOutOfBounds, p.PauseStart.Format(time.DateTime))
+		}
+	}
+
+	return nil
+}
+
+// checkOverlap refuses a session that overlaps any other. A session without
+// an end runs up to now, so it overlaps anything after its start.
+func checkOverlap(tx *sql.Tx, session Session) error {
+	query := `SELECT id FROM sessions WHERE id != ? AND (end_time IS NULL OR end_time > ?)`
+	args := []any{session.ID, session.StartTime}
+	if session.Endtime != nil {
+		query += ` AND start_time < ?`
+		args = append(args, session.Endtime)
+	}
+	query += ` ORDER BY start_time LIMIT 1`
+
+	var overlapping int64
+	err := tx.QueryRow(query, args...).Scan(&overlapping)
+	if err == nil {
+		return fmt.Errorf("%w %d", ErrOverlappingSession, overlapping)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+// getSession reads a session with its tags and pauses inside a transaction.
+func getSession(tx *sql.Tx, sessionID int64) (*Session, error) {
+	session, err := scanSession(tx.QueryRow(`SELECT `+sessionColumns+` FROM sessions WHERE id = ?`, sessionID))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`SELECT tag FROM session_tags WHERE session_id = ? ORDER BY tag ASC`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	err = forEachRow(rows, func(rows *sql.Rows) error {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return err
+		}
+		session.Tags = append(session.Tags, tag)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(`
+		SELECT `+pauseColumns+`
+		FROM pauses
+		WHERE session_id = ?
+		ORDER BY pause_start ASC
+		`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	err = forEachRow(rows, func(rows *sql.Rows) error {
+		p, err := scanPause(rows)
+		if err != nil {
+			return err
+		}
+		session.Pauses = append(session.Pauses, p)
+		return nil
+	})
+	return &session, err
+}
+
+func recordAudit(tx *sql.Tx, sessionID int64, action AuditAction, before, after *Session) error {
+	beforeJSON, err := encodeSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := encodeSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO session_audit (session_id, action, before, after, changed_at)
+		VALUES (?, ?, ?, ?, ?)
+		`, sessionID, action, beforeJSON, afterJSON, time.Now().UTC())
+	return err
+}
+
+func encodeSnapshot(session *Session) (any, error) {
+	if session == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func decodeSnapshot(data sql.NullString) (*Session, error) {
+	if !data.Valid {
+		return nil, nil
+	}
+	var session Session
+	if err := json.Unmarshal([]byte(data.String), &session); err != nil {
+		return nil, fmt.Errorf("corrupt audit entry: %w", err)
+	}
+	return &session, nil
+}
+
+// inUTC converts the times of a session and its pauses to UTC, since times are
+// compared as stored text.
+func inUTC(session Session) Session {
+	session.StartTime = session.StartTime.UTC()
+	if session.Endtime != nil {
+		end := session.Endtime.UTC()
+		session.Endtime = &end
+	}
+
+	pauses := make([]Pause, len(session.Pauses))
+	for i, p := range session.Pauses {
+		p.PauseStart = p.PauseStart.UTC()
+		if p.PauseEnd != nil {
+			end := p.PauseEnd.UTC()
+			p.PauseEnd = &end
+		}
+		pauses[i] = p
+	}
+	session.Pauses = pauses
+	return session
+}
@@ -0,0 +1,444 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// eventType names a change in the event log. Every change to sessions and
+// pauses is appended to the log in the same transaction, so the tables can
+// be rebuilt from it alone.
+type eventType string
+
+const (
+	eventSessionStarted eventType = "session_started"
+	eventPaused         eventType = "paused"
+	eventResumed        eventType = "resumed"
+	eventCompleted      eventType = "completed"
+	eventIdleDecided    eventType = "idle_decided"
+	// eventEdited carries the whole session with its pauses after the change
+	eventEdited  eventType = "edited"
+	eventDeleted eventType = "deleted"
+)
+
+type event struct {
+	ID        int64
+	Type      eventType
+	SessionID int64
+	PauseID   int64
+	At        time.Time
+	Data      string
+}
+
+type startedData struct {
+	Branch      string        `json:"branch"`
+	Ticket      string        `json:"ticket"`
+	Client      string        `json:"client"`
+	Project     string        `json:"project"`
+	RepoPath    string        `json:"repo_path"`
+	RepoID      string        `json:"repo_id"`
+	StartCommit string        `json:"start_commit"`
+	Origin      SessionOrigin `json:"origin"`
+	Tags        []string      `json:"tags"`
+}
+
+type pausedData struct {
+	Reason PauseReason `json:"reason"`
+	Note   string      `json:"note"`
+	IsAfk  bool        `json:"is_afk"`
+}
+
+type idleDecidedData struct {
+	Decision IdleDecision `json:"decision"`
+	Activity string       `json:"activity"`
+}
+
+type completedData struct {
+	EndCommit string `json:"end_commit"`
+}
+
+// RebuildResult tells what replaying the event log found.
+type RebuildResult struct {
+	Events   int
+	Sessions int
+	// Differences lists where the rebuilt sessions and pauses differ from
+	// the ones that were in the tables
+	Differences []string
+}
+
+func recordEvent(tx *sql.Tx, e event) error {
+	if e.Data == "" {
+		e.Data = "{}"
+	}
+	_, err := tx.Exec(`
+		INSERT INTO events (type, session_id, pause_id, at, data, recorded_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		`, e.Type, e.SessionID, nullID(e.PauseID), e.At.UTC(), e.Data, time.Now().UTC())
+	return err
+}
+
+func recordEventData(tx *sql.Tx, e event, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	e.Data = string(raw)
+	return recordEvent(tx, e)
+}
+
+// recordEdited logs the current state of a session after a change that is
+// not one of the tracker's own transitions.
+func recordEdited(tx *sql.Tx, sessionID int64) error {
+	session, err := getSession(tx, sessionID)
+	if err != nil {
+		return err
+	}
+	return recordEventData(tx, event{Type: eventEdited, SessionID: sessionID, At: time.Now().UTC()}, session)
+}
+
+// RebuildFromEvents implements DB.
+func (s *sqliteDB) RebuildFromEvents(dryRun bool) (RebuildResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return RebuildResult{}, err
+	}
+	defer tx.Rollback()
+
+	before, err := allSessions(tx)
+	if err != nil {
+		return RebuildResult{}, err
+	}
+
+	events, err := loadEvents(tx)
+	if err != nil {
+		return RebuildResult{}, err
+	}
+
+	for _, table := range []string{"pauses", "session_tags", "sessions"} {
+		if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+			return RebuildResult{}, err
+		}
+	}
+	for _, e := range events {
+		if err := applyEvent(tx, e); err != nil {
+			return RebuildResult{}, fmt.Errorf("event %d (%s of session %d): %w", e.ID, e.Type, e.SessionID, err)
+		}
+	}
+	_, err = tx.Exec(`
+		UPDATE sessions SET
+			is_paused = EXISTS (SELECT 1 FROM pauses p WHERE p.session_id = sessions.id AND p.pause_end IS NULL),
+			is_afk = COALESCE((SELECT p.is_afk FROM pauses p WHERE p.session_id = sessions.id AND p.pause_end IS NULL ORDER BY p.pause_start DESC LIMIT 1), 0)
+		`)
+	if err != nil {
+		return RebuildResult{}, err
+	}
+
+	after, err := allSessions(tx)
+	if err != nil {
+		return RebuildResult{}, err
+	}
+
+	result := RebuildResult{Events: len(events), Sessions: len(after), Differences: diffSessions(before, after)}
+	if dryRun {
+		return result, nil
+	}
+	return result, tx.Commit()
+}
+
+func loadEvents(tx *sql.Tx) ([]event, error) {
+	rows, err := tx.Query(`SELECT id, type, session_id, COALESCE(pause_id, 0), at, data FROM events ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []event
+	err = forEachRow(rows, func(rows *sql.Rows) error {
+		var e event
+		if err := rows.Scan(&e.ID, &e.Type, &e.SessionID, &e.PauseID, &e.At, &e.Data); err != nil {
+			return err
+		}
+		events = append(events, e)
+		return nil
+	})
+	return events, err
+}
+
+// applyEvent replays one event onto the tables, without logging it again.
+func applyEvent(tx *sql.Tx, e event) error {
+	switch e.Type {
+	case eventSessionStarted:
+		var data startedData
+		if err := json.Unmarshal([]byte(e.Data), &data); err != nil {
+			return err
+		}
+		return restoreSession(tx, Session{
+			ID:          e.SessionID,
+			Branch:      data.Branch,
+			Ticket:      data.Ticket,
+			Client:      data.Client,
+			Project:     data.Project,
+			RepoPath:    data.RepoPath,
+			RepoID:      data.RepoID,
+			StartCommit: data.StartCommit,
+			StartTime:   e.At,
+			Origin:      data.Origin,
+			Tags:        data.Tags,
+		})
+
+	case eventPaused:
+		var data pausedData
+		if err := json.Unmarshal([]byte(e.Data), &data); err != nil {
+			return err
+		}
+		return restorePause(tx, Pause{ID: e.PauseID, SessionID: e.SessionID, PauseStart: e.At, IsAfk: data.IsAfk, Reason: data.Reason, Note: data.Note})
+
+	case eventResumed:
+		_, err := tx.Exec(`UPDATE pauses SET pause_end = ? WHERE id = ?`, e.At, e.PauseID)
+		return err
+
+	case eventIdleDecided:
+		var data idleDecidedData
+		if err := json.Unmarshal([]byte(e.Data), &data); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`UPDATE pauses SET decision = ?, activity = ? WHERE id = ?`, data.Decision, data.Activity, e.PauseID)
+		return err
+
+	case eventCompleted:
+		var data completedData
+		if err := json.Unmarshal([]byte(e.Data), &data); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE pauses SET pause_end = ? WHERE session_id = ? AND pause_end IS NULL`, e.At, e.SessionID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`UPDATE sessions SET end_time = ?, end_commit = ? WHERE id = ?`, e.At, data.EndCommit, e.SessionID)
+		return err
+
+	case eventEdited:
+		var session Session
+		if err := json.Unmarshal([]byte(e.Data), &session); err != nil {
+			return err
+		}
+		if err := removeSession(tx, e.SessionID); err != nil {
+			return err
+		}
+		session.ID = e.SessionID
+		if err := restoreSession(tx, session); err != nil {
+			return err
+		}
+		for _, p := range session.Pauses {
+			p.SessionID = e.SessionID
+			if err := restorePause(tx, p); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case eventDeleted:
+		return removeSession(tx, e.SessionID)
+
+	default:
+		return fmt.Errorf("unknown event type %q", e.Type)
+	}
+}
+
+// restoreSession inserts a session under its original ID.
+func restoreSession(tx *sql.Tx, session Session) error {
+	if session.Origin == "" {
+		session.Origin = OriginTracked
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO sessions (id, branch, ticket, client, project, repo_path, repo_id, start_commit, end_commit, start_time, end_time, origin, is_paused, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		`, session.ID, session.Branch, session.Ticket, session.Client, session.Project, session.RepoPath, session.RepoID, session.StartCommit, session.EndCommit, session.StartTime.UTC(), utcOrNil(session.Endtime), session.Origin)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range session.Tags {
+		_, err = tx.Exec(`INSERT OR IGNORE INTO session_tags (session_id, tag) VALUES (?, ?)`, session.ID, tag)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restorePause inserts a pause under its original ID.
+func restorePause(tx *sql.Tx, p Pause) error {
+	if p.Reason == "" {
+		p.Reason = PauseReasonManual
+	}
+	_, err := tx.Exec(`
+		INSERT INTO pauses (id, session_id, pause_start, pause_end, is_afk, reason, note, decision, activity)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, nullID(p.ID), p.SessionID, p.PauseStart.UTC(), utcOrNil(p.PauseEnd), p.IsAfk, p.Reason, p.Note, p.Decision, p.Activity)
+	return err
+}
+
+// removeSession drops the rows of a session that the event log rebuilds.
+func removeSession(tx *sql.Tx, sessionID int64) error {
+	for _, table := range []string{"pauses", "session_tags"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE session_id = ?`, sessionID); err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec(`DELETE FROM sessions WHERE id = ?`, sessionID)
+	return err
+}
+
+func allSessions(tx *sql.Tx) (map[int64]*Session, error) {
+	rows, err := tx.Query(`SELECT id FROM sessions`)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	err = forEachRow(rows, func(rows *sql.Rows) error {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make(map[int64]*Session, len(ids))
+	for _, id := range ids {
+		if sessions[id], err = getSession(tx, id); err != nil {
+			return nil, err
+		}
+	}
+	return sessions, nil
+}
+
+// diffSessions describes how the sessions in after differ from those in
+// before, ignoring bookkeeping such as created_at.
+func diffSessions(before, after map[int64]*Session) []string {
+	var ids []int64
+	for id := range before {
+		ids = append(ids, id)
+	}
+	for id := range after {
+		if before[id] == nil {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	var diffs []string
+	for _, id := range ids {
+		b, a := before[id], after[id]
+		switch {
+		case a == nil:
+			diffs = append(diffs, fmt.Sprintf("session %d is not in the event log", id))
+		case b == nil:
+			diffs = append(diffs, fmt.Sprintf("session %d is only in the event log", id))
+		default:
+			diffs = append(diffs, diffSession(b, a)...)
+		}
+	}
+	return diffs
+}
+
+func diffSession(b, a *Session) []string {
+	var diffs []string
+	field := func(name string, differs bool, was, is any) {
+		if differs {
+			diffs = append(diffs, fmt.Sprintf("session %d: %s was %v, events give %v", b.ID, name, was, is))
+		}
+	}
+	field("branch", b.Branch != a.Branch, b.Branch, a.Branch)
+	field("ticket", b.Ticket != a.Ticket, b.Ticket, a.Ticket)
+	field("client", b.Client != a.Client, b.Client, a.Client)
+	field("project", b.Project != a.Project, b.Project, a.Project)
+	field("repository path", b.RepoPath != a.RepoPath, b.RepoPath, a.RepoPath)
+	field("repository id", b.RepoID != a.RepoID, b.RepoID, a.RepoID)
+	field("origin", b.Origin != a.Origin, b.Origin, a.Origin)
+	field("start", !b.StartTime.Equal(a.StartTime), b.StartTime, a.StartTime)
+	field("end", !equalTimes(b.Endtime, a.Endtime), timeOrNone(b.Endtime), timeOrNone(a.Endtime))
+	field("start commit", b.StartCommit != a.StartCommit, b.StartCommit, a.StartCommit)
+	field("end commit", b.EndCommit != a.EndCommit, b.EndCommit, a.EndCommit)
+	field("paused", b.IsPaused != a.IsPaused || b.IsAfk != a.IsAfk, pausedState(b), pausedState(a))
+	field("tags", !slices.Equal(b.Tags, a.Tags), b.Tags, a.Tags)
+
+	pauses := func(s *Session) map[int64]Pause {
+		m := map[int64]Pause{}
+		for _, p := range s.Pauses {
+			m[p.ID] = p
+		}
+		return m
+	}
+	bp, ap := pauses(b), pauses(a)
+	for _, p := range b.Pauses {
+		id := p.ID
+		q, ok := ap[id]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("session %d: pause %d is not in the event log", b.ID, id))
+		case !p.PauseStart.Equal(q.PauseStart) || !equalTimes(p.PauseEnd, q.PauseEnd) || p.Reason != q.Reason || p.IsAfk != q.IsAfk:
+			diffs = append(diffs, fmt.Sprintf("session %d: pause %d was %v – %v (%s), events give %v – %v (%s)",
+				b.ID, id, p.PauseStart, timeOrNone(p.PauseEnd), p.Reason, q.PauseStart, timeOrNone(q.PauseEnd), q.Reason))
+		case p.Note != q.Note:
+			diffs = append(diffs, fmt.Sprintf("session %d: pause %d note was %q, events give %q", b.ID, id, p.Note, q.Note))
+		case p.Decision != q.Decision || p.Activity != q.Activity:
+			diffs = append(diffs, fmt.Sprintf("session %d: pause %d was %s, events give %s", b.ID, id, decisionOf(p), decisionOf(q)))
+		}
+	}
+	for _, q := range a.Pauses {
+		if _, ok := bp[q.ID]; !ok {
+			id := q.ID
+			diffs = append(diffs, fmt.Sprintf("session %d: pause %d is only in the event log", b.ID, id))
+		}
+	}
+	return diffs
+}
+
+func decisionOf(p Pause) string {
+	switch {
+	case p.Decision == IdleUndecided:
+		return "undecided"
+	case p.Activity != "":
+		return fmt.Sprintf("%s (%s)", p.Decision, p.Activity)
+	}
+	return string(p.Decision)
+}
+
+func pausedState(s *Session) string {
+	switch {
+	case s.IsAfk:
+		return "afk"
+	case s.IsPaused:
+		return "paused"
+	}
+	return "running"
+}
+
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func timeOrNone(t *time.Time) any {
+	if t == nil {
+		return "none"
+	}
+	return *t
+}
+
+func utcOrNil(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,200 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const operationQuery = `
+	SELECT o.id, o.kind, o.session_id, COALESCE(s.branch, ''), COALESCE(o.pause_id, 0), COALESCE(o.next_session_id, 0), o.at
+	FROM operations o
+	LEFT JOIN sessions s ON s.id = o.session_id
+	WHERE o.undone_at IS NULL
+	ORDER BY o.id DESC
+	LIMIT ?`
+
+func scanOperation(row rowScanner) (Operation, error) {
+	var op Operation
+	err := row.Scan(&op.ID, &op.Kind, &op.SessionID, &op.Branch, &op.PauseID, &op.NextSessionID, &op.At)
+	return op, err
+}
+
+// ListOperations implements DB.
+func (s *sqliteDB) ListOperations(limit int) ([]Operation, error) {
+	rows, err := s.db.Query(operationQuery, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []Operation
+	err = forEachRow(rows, func(rows *sql.Rows) error {
+		op, err := scanOperation(rows)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, op)
+		return nil
+	})
+	return ops, err
+}
+
+// UndoLastOperation implements DB.
+func (s *sqliteDB) UndoLastOperation() (Operation, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Operation{}, err
+	}
+	defer tx.Rollback()
+
+	op, err := scanOperation(tx.QueryRow(operationQuery, 1))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Operation{}, ErrNothingToUndo
+	}
+	if err != nil {
+		return Operation{}, err
+	}
+
+	switch op.Kind {
+	case OpStart:
+		err = undoStart(tx, op.SessionID)
+	case OpPause:
+		err = undoPause(tx, op)
+	case OpResume:
+		err = undoResume(tx, op)
+	case OpComplete:
+		err = reopenSession(tx, op.SessionID, op.PauseID)
+	case OpSwitch:
+		if err = undoStart(tx, op.NextSessionID); err == nil {
+			err = reopenSession(tx, op.SessionID, op.PauseID)
+		}
+	default:
+		err = fmt.Errorf("unknown operation %q", op.Kind)
+	}
+	if err != nil {
+		return Operation{}, fmt.Errorf("%s %w", op.Kind, err)
+	}
+	if op.Kind != OpStart {
+		if err := recordEdited(tx, op.SessionID); err != nil {
+			return Operation{}, err
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE operations SET undone_at = ? WHERE id = ?`, time.Now().UTC(), op.ID); err != nil {
+		return Operation{}, err
+	}
+
+	return op, tx.Commit()
+}
+
+func undoStart(tx *sql.Tx, sessionID int64) error {
+	var exists bool
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM sessions WHERE id = ?)`, sessionID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: session %d no longer exists", ErrCannotUndo, sessionID)
+	}
+	return deleteSession(tx, sessionID)
+}
+
+func undoPause(tx *sql.Tx, op Operation) error {
+	res, err := tx.Exec(`DELETE FROM pauses WHERE id = ? AND pause_end IS NULL`, op.PauseID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: the pause has already ended", ErrCannotUndo)
+	}
+	return refreshPauseState(tx, op.SessionID)
+}
+
+func undoResume(tx *sql.Tx, op Operation) error {
+	var open int
+	err := tx.QueryRow(`
+		SELECT COUNT(*) FROM pauses p JOIN sessions s ON s.id = p.session_id
+		WHERE s.id = ? AND s.end_time IS NULL AND p.pause_end IS NULL
+		`, op.SessionID).Scan(&open)
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return fmt.Errorf("%w: the session is paused again", ErrCannotUndo)
+	}
+
+	res, err := tx.Exec(`
+		UPDATE pauses SET pause_end = NULL, decision = '', activity = ''
+		WHERE id = ? AND session_id IN (SELECT id FROM sessions WHERE end_time IS NULL)
+		`, op.PauseID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: the session has ended", ErrCannotUndo)
+	}
+	return refreshPauseState(tx, op.SessionID)
+}
+
+// reopenSession makes a completed session active again, paused if pauseID
+// was closed when it was completed.
+func reopenSession(tx *sql.Tx, sessionID, pauseID int64) error {
+	var active int64
+	err := tx.QueryRow(`SELECT id FROM sessions WHERE end_time IS NULL AND id != ? LIMIT 1`, sessionID).Scan(&active)
+	if err == nil {
+		return fmt.Errorf("%w: session %d is active", ErrCannotUndo, active)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	res, err := tx.Exec(`UPDATE sessions SET end_time = NULL, end_commit = '' WHERE id = ? AND end_time IS NOT NULL`, sessionID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: session %d no longer exists", ErrCannotUndo, sessionID)
+	}
+
+	if pauseID != 0 {
+		if _, err := tx.Exec(`UPDATE pauses SET pause_end = NULL, decision = '', activity = '' WHERE id = ?`, pauseID); err != nil {
+			return err
+		}
+	}
+	return refreshPauseState(tx, sessionID)
+}
+
+// refreshPauseState derives the paused and AFK flags of a session from its
+// open pause.
+func refreshPauseState(tx *sql.Tx, sessionID int64) error {
+	_, err := tx.Exec(`
+		UPDATE sessions SET
+			is_paused = EXISTS (SELECT 1 FROM pauses WHERE session_id = ?1 AND pause_end IS NULL),
+			is_afk = COALESCE((SELECT is_afk FROM pauses WHERE session_id = ?1 AND pause_end IS NULL ORDER BY pause_start DESC LIMIT 1), 0),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?1
+		`, sessionID)
+	return err
+}
+
+func recordOperation(tx *sql.Tx, op Operation) error {
+	_, err := tx.Exec(`
+		INSERT INTO operations (kind, session_id, pause_id, next_session_id, at)
+		VALUES (?, ?, ?, ?, ?)
+		`, op.Kind, op.SessionID, nullID(op.PauseID), nullID(op.NextSessionID), op.At.UTC())
+	return err
+}
+
+// forgetOperations drops the journal entries of a session that was changed
+// by hand, since undo could no longer revert them faithfully.
+func forgetOperations(tx *sql.Tx, sessionID int64) error {
+	_, err := tx.Exec(`DELETE FROM operations WHERE session_id = ? OR next_session_id = ?`, sessionID, sessionID)
+	return err
+}
+
+func nullID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+const sessionColumns = `id, branch, ticket, client, project, repo_path, repo_id, start_commit, end_commit, start_time, end_time, is_paused, is_afk, origin, created_at, updated_at`
+
+const pauseColumns = `id, session_id, pause_start, pause_end, is_afk, reason, note, decision, activity`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSession(row rowScanner) (Session, error) {
+	var session Session
+	err := row.Scan(
+		&session.ID,
+		&session.Branch,
+		&session.Ticket,
+		&session.Client,
+		&session.Project,
+		&session.RepoPath,
+		&session.RepoID,
+		&session.StartCommit,
+		&session.EndCommit,
+		&session.StartTime,
+		&session.Endtime,
+		&session.IsPaused,
+		&session.IsAfk,
+		&session.Origin,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+	return session, err
+}
+
+func scanPause(row rowScanner) (Pause, error) {
+	var p Pause
+	err := row.Scan(&p.ID, &p.SessionID, &p.PauseStart, &p.PauseEnd, &p.IsAfk, &p.Reason, &p.Note, &p.Decision, &p.Activity)
+	return p, err
+}
+
+// ListSessions implements DB.
+func (s *sqliteDB) ListSessions(filter SessionFilter) ([]Session, error) {
+	var where []string
+	var args []any
+
+	if !filter.To.IsZero() {
+		where = append(where, `start_time < ?`)
+		args = append(args, filter.To.UTC())
+	}
+	if !filter.From.IsZero() {
+		where = append(where, `(end_time IS NULL OR end_time > ?)`)
+		args = append(args, filter.From.UTC())
+	}
+	if filter.Branch != "" {
+		where = append(where, `branch GLOB ?`)
+		args = append(args, filter.Branch)
+	}
+	if filter.Client != "" {
+		where = append(where, `client = ?`)
+		args = append(args, filter.Client)
+	}
+
+	if filter.Project != "" {
+		where = append(where, `project = ?`)
+		args = append(args, filter.Project)
+	}
+
+	if filter.Ticket != "" {
+		where = append(where, `ticket = ?`)
+		args = append(args, filter.Ticket)
+	}
+	if filter.Repo != "" {
+		where = append(where, `(repo_id = ? OR repo_path = ?)`)
+		args = append(args, filter.Repo, filter.Repo)
+	}
+	if filter.Tag != "" {
+		where = append(where, `id IN (SELECT session_id FROM session_tags WHERE tag = ?)`)
+		args = append(args, filter.Tag)
+	}
+	if filter.Unsynced {
+		where = append(where, `id NOT IN (SELECT session_id FROM session_worklogs)`)
+	}
+	switch filter.State {
+	case ActiveSessions:
+		where = append(where, `end_time IS NULL`)
+	case CompletedSessions:
+		where = append(where, `end_time IS NOT NULL`)
+	}
+
+	query := `SELECT ` + sessionColumns + ` FROM sessions`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	if filter.Order == NewestFirst {
+		query += ` ORDER BY start_time DESC, id DESC`
+	} else {
+		query += ` ORDER BY start_time ASC, id ASC`
+	}
+	if filter.Limit > 0 || filter.Offset > 0 {
+		limit := filter.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, filter.Offset)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := s.loadSessionDetails(sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// detailsBatchSize keeps the IN lists below SQLite's bound parameter limit
+const detailsBatchSize = 500
+
+// loadSessionDetails fills in the tags and pauses of sessions with a couple of
+// batched queries instead of two per session.
+func (s *sqliteDB) loadSessionDetails(sessions []Session) error {
+	for len(sessions) > 0 {
+		n := min(len(sessions), detailsBatchSize)
+		if err := s.loadSessionDetailsBatch(sessions[:n]); err != nil {
+			return err
+		}
+		sessions = sessions[n:]
+	}
+	return nil
+}
+
+func (s *sqliteDB) loadSessionDetailsBatch(sessions []Session) error {
+	index := make(map[int64]int, len(sessions))
+	ids := make([]any, len(sessions))
+	for i, session := range sessions {
+		index[session.ID] = i
+		ids[i] = session.ID
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+
+	tagRows, err := s.db.Query(fmt.Sprintf(`
+		SELECT session_id, tag FROM session_tags
+		WHERE session_id IN (%s)
+		ORDER BY tag ASC
+		`, placeholders), ids...)
+	if err != nil {
+		return err
+	}
+	err = forEachRow(tagRows, func(rows *sql.Rows) error {
+		var sessionID int64
+		var tag string
+		if err := rows.Scan(&sessionID, &tag); err != nil {
+			return err
+		}
+		sessions[index[sessionID]].Tags = append(sessions[index[sessionID]].Tags, tag)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	pauseRows, err := s.db.Query(fmt.Sprintf(`
+		SELECT `+pauseColumns+`
+		FROM pauses
+		WHERE session_id IN (%s)
+		ORDER BY pause_start ASC
+		`, placeholders), ids...)
+	if err != nil {
+		return err
+	}
+	return forEachRow(pauseRows, func(rows *sql.Rows) error {
+		p, err := scanPause(rows)
+		if err != nil {
+			return err
+		}
+		sessions[index[p.SessionID]].Pauses = append(sessions[index[p.SessionID]].Pauses, p)
+		return nil
+	})
+}
+
+func forEachRow(rows *sql.Rows, fn func(*sql.Rows) error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
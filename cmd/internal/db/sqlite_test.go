@@ -0,0 +1,887 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestDB(t *testing.T) DB {
+	t.Helper()
+
+	sqlite, err := NewSQLiteDB(filepath.Join(t.TempDir(), "lofi-tracker.db"))
+	if err != nil {
+		t.Fatalf("expected no error opening database, got %v", err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+
+	return sqlite
+}
+
+func TestPauseSession_ShouldStoreReasonAndNote(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	start := time.Now().UTC().Add(-time.Hour)
+	sessionID, err := sqlite.CreateSession(Session{Branch: "main", StartTime: start})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := sqlite.PauseSession(sessionID, start.Add(10*time.Minute), PauseReasonMeeting, "standup"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	session, err := sqlite.GetActiveSession()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !session.IsPaused || session.IsAfk {
+		t.Errorf("expected a manual pause, got paused=%v afk=%v", session.IsPaused, session.IsAfk)
+	}
+
+	if err := sqlite.ResumeSession(sessionID, start.Add(25*time.Minute)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := sqlite.PauseSession(sessionID, start.Add(40*time.Minute), PauseReasonScreenLock, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	pauses, err := sqlite.ListPauses(sessionID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(pauses) != 2 {
+		t.Fatalf("expected 2 pauses, got %d", len(pauses))
+	}
+
+	if pauses[0].Reason != PauseReasonMeeting || pauses[0].Note != "standup" || pauses[0].IsAfk {
+		t.Errorf("expected a manual meeting pause with note, got %+v", pauses[0])
+	}
+	if pauses[1].Reason != PauseReasonScreenLock || !pauses[1].IsAfk || pauses[1].PauseEnd != nil {
+		t.Errorf("expected an open AFK screen-lock pause, got %+v", pauses[1])
+	}
+}
+
+func TestParsePauseReason(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    PauseReason
+		wantErr bool
+	}{
+		{in: "", want: PauseReasonManual},
+		{in: " Lunch ", want: PauseReasonLunch},
+		{in: "screen-lock", want: PauseReasonScreenLock},
+		{in: "doctor_visit", want: PauseReason("doctor_visit")},
+		{in: "coffee break", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePauseReason(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidPauseReason) {
+				t.Errorf("ParsePauseReason(%q): expected ErrInvalidPauseReason, got %v", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParsePauseReason(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestListSessions_WithRange_ShouldReturnOverlappingSessionsWithPauses(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	day := time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC)
+	before, _ := sqlite.CreateSession(Session{Branch: "before", StartTime: day.Add(-3 * time.Hour)})
+	sqlite.CompleteSession(before, day.Add(-time.Hour), "")
+	crossing, _ := sqlite.CreateSession(Session{Branch: "crossing", StartTime: day.Add(-time.Hour)})
+	sqlite.PauseSession(crossing, day.Add(time.Hour), PauseReasonLunch, "")
+	sqlite.ResumeSession(crossing, day.Add(2*time.Hour))
+	sqlite.CompleteSession(crossing, day.Add(3*time.Hour), "")
+	open, _ := sqlite.CreateSession(Session{Branch: "open", StartTime: day.Add(20 * time.Hour)})
+
+	sessions, err := sqlite.ListSessions(SessionFilter{From: day, To: day.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(sessions) != 2 || sessions[0].ID != crossing || sessions[1].ID != open {
+		t.Fatalf("expected the crossing and the open session, got %+v", sessions)
+	}
+	if len(sessions[0].Pauses) != 1 || sessions[0].Pauses[0].Reason != PauseReasonLunch {
+		t.Errorf("expected the lunch pause to be loaded, got %+v", sessions[0].Pauses)
+	}
+}
+
+func TestListSessions_ShouldApplyFilters(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	start := time.Date(2026, time.October, 12, 9, 0, 0, 0, time.UTC)
+	seed := []Session{
+		{Branch: "feature/ABC-1", RepoID: "repo-a", RepoPath: "/src/a", Tags: []string{"client-x"}},
+		{Branch: "feature/ABC-2", RepoID: "repo-a", RepoPath: "/src/a"},
+		{Branch: "main", RepoID: "repo-b", RepoPath: "/src/b", Tags: []string{"client-x", "ops"}},
+		{Branch: "bugfix/ABC-1", RepoID: "repo-b", RepoPath: "/src/b"},
+	}
+	var ids []int64
+	for i, session := range seed {
+		session.StartTime = start.Add(time.Duration(i) * time.Hour)
+		id, err := sqlite.CreateSession(session)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if i < len(seed)-1 {
+			sqlite.CompleteSession(id, session.StartTime.Add(30*time.Minute), "")
+		}
+		ids = append(ids, id)
+	}
+
+	tests := []struct {
+		name   string
+		filter SessionFilter
+		want   []int64
+	}{
+		{name: "branch glob", filter: SessionFilter{Branch: "feature/*"}, want: []int64{ids[0], ids[1]}},
+		{name: "repo id", filter: SessionFilter{Repo: "repo-b"}, want: []int64{ids[2], ids[3]}},
+		{name: "repo path", filter: SessionFilter{Repo: "/src/a"}, want: []int64{ids[0], ids[1]}},
+		{name: "tag", filter: SessionFilter{Tag: "client-x"}, want: []int64{ids[0], ids[2]}},
+		{name: "active", filter: SessionFilter{State: ActiveSessions}, want: []int64{ids[3]}},
+		{name: "completed newest first", filter: SessionFilter{State: CompletedSessions, Order: NewestFirst}, want: []int64{ids[2], ids[1], ids[0]}},
+		{name: "page", filter: SessionFilter{Limit: 2, Offset: 1}, want: []int64{ids[1], ids[2]}},
+		{name: "offset only", filter: SessionFilter{Offset: 3}, want: []int64{ids[3]}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions, err := sqlite.ListSessions(tt.filter)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			var got []int64
+			for _, s := range sessions {
+				got = append(got, s.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("expected sessions %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	sessions, _ := sqlite.ListSessions(SessionFilter{Tag: "ops"})
+	if len(sessions) != 1 || fmt.Sprint(sessions[0].Tags) != "[client-x ops]" {
+		t.Errorf("expected tags to be loaded, got %+v", sessions)
+	}
+}
+
+func TestSwitchSession_ShouldEndAndStartAtTheSameInstant(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	sessionID, err := sqlite.CreateSession(Session{Branch: "main", StartTime: start})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := sqlite.PauseSession(sessionID, start.Add(50*time.Minute), PauseReasonManual, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	at := start.Add(time.Hour)
+	nextID, err := sqlite.SwitchSession(sessionID, at, "abc123", Session{Branch: "feature/ABC-1", Ticket: "ABC-1", Tags: []string{"billable"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	active, err := sqlite.GetActiveSession()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if active.ID != nextID || active.Branch != "feature/ABC-1" || !active.StartTime.Equal(at) {
+		t.Errorf("expected feature/ABC-1 to start at %v, got %+v", at, active)
+	}
+
+	sessions, err := sqlite.ListSessions(SessionFilter{State: CompletedSessions})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Endtime == nil || !sessions[0].Endtime.Equal(at) || sessions[0].EndCommit != "abc123" {
+		t.Fatalf("expected main to end at %v, got %+v", at, sessions)
+	}
+	if p := sessions[0].Pauses; len(p) != 1 || p[0].PauseEnd == nil || !p[0].PauseEnd.Equal(at) {
+		t.Errorf("expected the open pause to end at %v, got %+v", at, p)
+	}
+
+	if _, err := sqlite.SwitchSession(sessionID, at, "", Session{Branch: "other"}); !errors.Is(err, ErrNoActiveSession) {
+		t.Errorf("expected switching from a completed session to fail, got %v", err)
+	}
+	all, err := sqlite.ListSessions(SessionFilter{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("expected the failed switch to start no session, got %d sessions", len(all))
+	}
+}
+
+func TestAddSession_ShouldStoreManualOriginAndRejectOverlaps(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	day := time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour)
+	trackedEnd := day.Add(12 * time.Hour)
+	trackedID, err := sqlite.CreateSession(Session{Branch: "main", StartTime: day.Add(10 * time.Hour)})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sqlite.CompleteSession(trackedID, trackedEnd, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	end := day.Add(10*time.Hour + 30*time.Minute)
+	if _, err := sqlite.AddSession(Session{Branch: "feature/ABC-1", StartTime: day.Add(9 * time.Hour), Endtime: &end, Origin: OriginManual}); !errors.Is(err, ErrOverlappingSession) {
+		t.Errorf("expected an overlap with the tracked session, got %v", err)
+	}
+
+	end = day.Add(10 * time.Hour)
+	id, err := sqlite.AddSession(Session{Branch: "feature/ABC-1", StartTime: day.Add(9 * time.Hour), Endtime: &end, Origin: OriginManual})
+	if err != nil {
+		t.Fatalf("expected a session ending where the next starts to be added, got %v", err)
+	}
+
+	sessions, err := sqlite.ListSessions(SessionFilter{Branch: "feature/ABC-1"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != id || sessions[0].Origin != OriginManual || sessions[0].Endtime == nil || !sessions[0].Endtime.Equal(end) {
+		t.Fatalf("expected the manual session ending at %v, got %+v", end, sessions)
+	}
+
+	tracked, err := sqlite.ListSessions(SessionFilter{Branch: "main"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(tracked) != 1 || tracked[0].Origin != OriginTracked {
+		t.Errorf("expected the live session to be tracked, got %+v", tracked)
+	}
+}
+
+func TestAddSession_WhenSessionIsActive_ShouldRejectTimeAfterItsStart(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	start := time.Now().UTC().Add(-time.Hour)
+	if _, err := sqlite.CreateSession(Session{Branch: "main", StartTime: start}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	end := start.Add(30 * time.Minute)
+	if _, err := sqlite.AddSession(Session{Branch: "other", StartTime: start.Add(-time.Hour), Endtime: &end}); !errors.Is(err, ErrOverlappingSession) {
+		t.Errorf("expected an overlap with the active session, got %v", err)
+	}
+
+	end = start.Add(-time.Hour)
+	if _, err := sqlite.AddSession(Session{Branch: "other", StartTime: start, Endtime: &end}); err == nil {
+		t.Errorf("expected a session ending before it starts to be rejected")
+	}
+}
+
+func TestUpdateSession_ShouldReplaceTimesAndPausesAndRecordAudit(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	start := time.Now().UTC().Add(-20 * time.Hour).Truncate(time.Second)
+	sessionID, err := sqlite.CreateSession(Session{Branch: "main", StartTime: start, Tags: []string{"billable"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	pauseID, err := sqlite.PauseSession(sessionID, start.Add(8*time.Hour), PauseReasonAfk, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	session, err := sqlite.GetSession(sessionID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	end := start.Add(9 * time.Hour)
+	pauseEnd := start.Add(8*time.Hour + 30*time.Minute)
+	session.Branch = "feature/ABC-1"
+	session.Endtime = &end
+	session.Pauses[0].PauseEnd = &pauseEnd
+	session.Pauses = append(session.Pauses, Pause{PauseStart: start.Add(time.Hour), PauseEnd: ptrTime(start.Add(2 * time.Hour)), Reason: PauseReasonLunch})
+
+	if err := sqlite.UpdateSession(*session); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	updated, err := sqlite.GetSession(sessionID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if updated.Branch != "feature/ABC-1" || updated.Endtime == nil || !updated.Endtime.Equal(end) || updated.IsPaused {
+		t.Errorf("expected a completed session on feature/ABC-1, got %+v", updated)
+	}
+	if len(updated.Pauses) != 2 || updated.Pauses[1].ID != pauseID || !updated.Pauses[1].PauseEnd.Equal(pauseEnd) {
+		t.Errorf("expected the AFK pause to keep its ID and end at %v, got %+v", pauseEnd, updated.Pauses)
+	}
+	if _, err := sqlite.GetActiveSession(); !errors.Is(err, ErrNoActiveSession) {
+		t.Errorf("expected no active session after setting an end, got %v", err)
+	}
+
+	audit, err := sqlite.ListAudit(sessionID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(audit) != 1 || audit[0].Action != AuditEdit || audit[0].Before.Branch != "main" || audit[0].After.Branch != "feature/ABC-1" {
+		t.Fatalf("expected one edit from main to feature/ABC-1, got %+v", audit)
+	}
+	if audit[0].Before.Endtime != nil || len(audit[0].Before.Tags) != 1 {
+		t.Errorf("expected the running session with its tag before the edit, got %+v", audit[0].Before)
+	}
+}
+
+func TestUpdateSession_WhenPauseIsOutsideSession_ShouldRefuse(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	start := time.Now().UTC().Add(-3 * time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+	sessionID, err := sqlite.AddSession(Session{Branch: "main", StartTime: start, Endtime: &end})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	session := Session{ID: sessionID, Branch: "main", StartTime: start, Endtime: &end, Pauses: []Pause{
+		{PauseStart: start.Add(90 * time.Minute), PauseEnd: ptrTime(start.Add(3 * time.Hour))},
+	}}
+	if err := sqlite.UpdateSession(session); !errors.Is(err, ErrPauseOutOfBounds) {
+		t.Errorf("expected a pause ending after the session to be refused, got %v", err)
+	}
+
+	audit, err := sqlite.ListAudit(sessionID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(audit) != 0 {
+		t.Errorf("expected a refused edit to leave no audit entry, got %+v", audit)
+	}
+}
+
+func TestDeleteSession_ShouldRemoveSessionAndKeepAuditCopy(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	start := time.Now().UTC().Add(-3 * time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+	sessionID, err := sqlite.AddSession(Session{Branch: "main", StartTime: start, Endtime: &end, Tags: []string{"billable"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := sqlite.DeleteSession(sessionID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := sqlite.GetSession(sessionID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("expected the session to be gone, got %v", err)
+	}
+	if err := sqlite.DeleteSession(sessionID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("expected deleting it again to fail, got %v", err)
+	}
+
+	audit, err := sqlite.ListAudit(0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(audit) != 1 || audit[0].Action != AuditDelete || audit[0].After != nil || audit[0].Before.Branch != "main" {
+		t.Fatalf("expected one delete of main, got %+v", audit)
+	}
+}
+
+func TestValidateSession(t *testing.T) {
+	start := time.Date(2026, time.October, 17, 9, 0, 0, 0, time.UTC)
+	end := start.Add(8 * time.Hour)
+	at := func(h int) *time.Time { return ptrTime(start.Add(time.Duration(h) * time.Hour)) }
+
+	cases := []struct {
+		name    string
+		session Session
+		want    error
+	}{
+		{"valid", Session{StartTime: start, Endtime: &end, Pauses: []Pause{{PauseStart: *at(1), PauseEnd: at(2)}, {PauseStart: *at(3), PauseEnd: at(4)}}}, nil},
+		{"end before start", Session{StartTime: start, Endtime: ptrTime(start.Add(-time.Hour))}, ErrInvalidSessionTimes},
+		{"pause before start", Session{StartTime: start, Endtime: &end, Pauses: []Pause{{PauseStart: start.Add(-time.Hour), PauseEnd: at(1)}}}, ErrPauseOutOfBounds},
+		{"pause after end", Session{StartTime: start, Endtime: &end, Pauses: []Pause{{PauseStart: *at(7), PauseEnd: at(9)}}}, ErrPauseOutOfBounds},
+		{"overlapping pauses", Session{StartTime: start, Endtime: &end, Pauses: []Pause{{PauseStart: *at(1), PauseEnd: at(3)}, {PauseStart: *at(2), PauseEnd: at(4)}}}, ErrPauseOutOfBounds},
+		{"open pause on completed session", Session{StartTime: start, Endtime: &end, Pauses: []Pause{{PauseStart: *at(1)}}}, ErrPauseOutOfBounds},
+		{"open pause on running session", Session{StartTime: start, Pauses: []Pause{{PauseStart: *at(1), PauseEnd: at(2)}, {PauseStart: *at(3)}}}, nil},
+	}
+	for _, c := range cases {
+		if err := ValidateSession(c.session); !errors.Is(err, c.want) || (c.want == nil && err != nil) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, err)
+		}
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func TestUndoLastOperation_ShouldWalkBackThroughTrackerActions(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	sessionID, err := sqlite.CreateSession(Session{Branch: "main", StartTime: start})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := sqlite.PauseSession(sessionID, start.Add(10*time.Minute), PauseReasonAfk, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	nextID, err := sqlite.SwitchSession(sessionID, start.Add(20*time.Minute), "", Session{Branch: "feature/ABC-1"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sqlite.CompleteSession(nextID, start.Add(30*time.Minute), ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ops, err := sqlite.ListOperations(10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	kinds := []OperationKind{OpComplete, OpSwitch, OpPause, OpStart}
+	if len(ops) != len(kinds) {
+		t.Fatalf("expected %d operations, got %+v", len(kinds), ops)
+	}
+	for i, kind := range kinds {
+		if ops[i].Kind != kind {
+			t.Errorf("expected operation %d to be %s, got %s", i, kind, ops[i].Kind)
+		}
+	}
+
+	// Undoing complete reopens feature/ABC-1
+	if op, err := sqlite.UndoLastOperation(); err != nil || op.Kind != OpComplete || op.Branch != "feature/ABC-1" {
+		t.Fatalf("expected to undo complete of feature/ABC-1, got %+v, %v", op, err)
+	}
+	active, err := sqlite.GetActiveSession()
+	if err != nil || active.ID != nextID {
+		t.Fatalf("expected session %d to be active again, got %+v, %v", nextID, active, err)
+	}
+
+	// Undoing switch drops feature/ABC-1 and resumes main, still paused
+	if _, err := sqlite.UndoLastOperation(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := sqlite.GetSession(nextID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("expected the session started by switch to be gone, got %v", err)
+	}
+	active, err = sqlite.GetActiveSession()
+	if err != nil || active.ID != sessionID || !active.IsPaused || !active.IsAfk {
+		t.Fatalf("expected main to be active and paused as AFK again, got %+v, %v", active, err)
+	}
+
+	// Undoing pause leaves main running without pauses
+	if _, err := sqlite.UndoLastOperation(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	active, err = sqlite.GetActiveSession()
+	if err != nil || active.IsPaused {
+		t.Fatalf("expected main to run unpaused, got %+v, %v", active, err)
+	}
+	if pauses, _ := sqlite.ListPauses(sessionID); len(pauses) != 0 {
+		t.Errorf("expected the pause to be removed, got %+v", pauses)
+	}
+
+	if op, err := sqlite.UndoLastOperation(); err != nil || op.Kind != OpStart {
+		t.Fatalf("expected to undo start, got %+v, %v", op, err)
+	}
+	if _, err := sqlite.UndoLastOperation(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("expected nothing left to undo, got %v", err)
+	}
+}
+
+func TestUndoLastOperation_WhenAnotherSessionIsActive_ShouldNotReopen(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	sessionID, err := sqlite.CreateSession(Session{Branch: "main", StartTime: start})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := sqlite.PauseSession(sessionID, start.Add(10*time.Minute), PauseReasonManual, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sqlite.ResumeSession(sessionID, start.Add(15*time.Minute)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sqlite.CompleteSession(sessionID, start.Add(20*time.Minute), ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Editing the next session drops it from the journal, but it still blocks
+	otherID, err := sqlite.CreateSession(Session{Branch: "other", StartTime: start.Add(30 * time.Minute)})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	other, err := sqlite.GetSession(otherID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sqlite.UpdateSession(*other); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := sqlite.UndoLastOperation(); !errors.Is(err, ErrCannotUndo) {
+		t.Fatalf("expected reopening main next to an active session to fail, got %v", err)
+	}
+
+	session, err := sqlite.GetSession(sessionID)
+	if err != nil || session.Endtime == nil {
+		t.Errorf("expected main to stay completed, got %+v, %v", session, err)
+	}
+}
+
+func TestRebuildFromEvents_ShouldReproduceTheTables(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	start := time.Now().UTC().Add(-5 * time.Hour).Truncate(time.Second)
+	firstID, err := sqlite.CreateSession(Session{Branch: "main", StartTime: start, Tags: []string{"billable"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := sqlite.PauseSession(firstID, start.Add(time.Hour), PauseReasonLunch, "pasta"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sqlite.ResumeSession(firstID, start.Add(90*time.Minute)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := sqlite.PauseSession(firstID, start.Add(2*time.Hour), PauseReasonAfk, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	secondID, err := sqlite.SwitchSession(firstID, start.Add(3*time.Hour), "abc", Session{Branch: "feature/ABC-1", Ticket: "ABC-1"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := sqlite.PauseSession(secondID, start.Add(4*time.Hour), PauseReasonManual, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	end := start.Add(-time.Hour)
+	manualID, err := sqlite.AddSession(Session{Branch: "manual", StartTime: start.Add(-2 * time.Hour), Endtime: &end, Origin: OriginManual})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	manual, err := sqlite.GetSession(manualID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	manual.Branch = "feature/ABC-2"
+	if err := sqlite.UpdateSession(*manual); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	end = start.Add(-3 * time.Hour)
+	deletedID, err := sqlite.AddSession(Session{Branch: "mistake", StartTime: start.Add(-4 * time.Hour), Endtime: &end})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sqlite.DeleteSession(deletedID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := sqlite.UndoLastOperation(); err != nil {
+		t.Fatalf("expected undoing the last pause to work, got %v", err)
+	}
+
+	result, err := sqlite.RebuildFromEvents(true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Sessions != 3 || len(result.Differences) != 0 {
+		t.Fatalf("expected 3 sessions matching the tables, got %+v", result)
+	}
+
+	// Tamper with the tables behind the log's back
+	conn := sqlite.(*sqliteDB).db
+	if _, err := conn.Exec(`UPDATE sessions SET end_time = NULL, is_paused = 1 WHERE id = ?`, firstID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := conn.Exec(`DELETE FROM pauses WHERE session_id = ?`, firstID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	result, err = sqlite.RebuildFromEvents(true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Differences) != 4 {
+		t.Errorf("expected end, paused state and two pauses to differ, got %q", result.Differences)
+	}
+	if session, _ := sqlite.GetSession(firstID); session.Endtime != nil {
+		t.Fatalf("expected a dry run to leave the tables alone, got %+v", session)
+	}
+
+	if _, err := sqlite.RebuildFromEvents(false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	session, err := sqlite.GetSession(firstID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if session.Endtime == nil || !session.Endtime.Equal(start.Add(3*time.Hour)) || session.IsPaused || len(session.Pauses) != 2 {
+		t.Errorf("expected main to be rebuilt as completed with two pauses, got %+v", session)
+	}
+	if p := session.Pauses[1]; p.Reason != PauseReasonAfk || !p.IsAfk || p.PauseEnd == nil || !p.PauseEnd.Equal(start.Add(3*time.Hour)) {
+		t.Errorf("expected the AFK pause to be closed by the switch, got %+v", p)
+	}
+	active, err := sqlite.GetActiveSession()
+	if err != nil || active.ID != secondID || active.IsPaused {
+		t.Errorf("expected feature/ABC-1 to be active and running, got %+v, %v", active, err)
+	}
+
+	// Columns besides times and state are compared too
+	if _, err := conn.Exec(`UPDATE sessions SET project = 'other', start_commit = 'def', origin = 'manual' WHERE id = ?`, secondID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := conn.Exec(`UPDATE pauses SET note = '' WHERE session_id = ? AND reason = ?`, firstID, PauseReasonLunch); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	result, err = sqlite.RebuildFromEvents(true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Differences) != 4 {
+		t.Errorf("expected project, start commit, origin and the pause note to differ, got %q", result.Differences)
+	}
+}
+
+func TestEventsMigration_ShouldSeedTheLogFromExistingSessions(t *testing.T) {
+	conn := openTestConn(t)
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var before []Migration
+	for _, m := range migrations {
+		if m.Name != "events" {
+			before = append(before, m)
+			continue
+		}
+		break
+	}
+	if _, err := runMigrations(conn, before); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = conn.Exec(`
+		INSERT INTO sessions (id, branch, ticket, start_time, end_time, end_commit, is_paused, is_afk) VALUES
+			(1, 'main', '', '2026-10-17 09:00:00+00:00', '2026-10-17 12:00:00+00:00', 'abc', 0, 0),
+			(2, 'feature/ABC-1', 'ABC-1', '2026-10-17 12:00:00+00:00', NULL, '', 1, 1);
+		INSERT INTO session_tags (session_id, tag) VALUES (2, 'billable');
+		INSERT INTO pauses (id, session_id, pause_start, pause_end, is_afk, reason, note) VALUES
+			(1, 1, '2026-10-17 10:00:00+00:00', '2026-10-17 10:30:00+00:00', 0, 'meeting', 'standup'),
+			(2, 2, '2026-10-17 13:00:00+00:00', NULL, 1, 'afk', '');
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := runMigrations(conn, migrations); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sqlite := &sqliteDB{db: conn}
+	result, err := sqlite.RebuildFromEvents(true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Events != 6 || result.Sessions != 2 || len(result.Differences) != 0 {
+		t.Errorf("expected 6 seeded events rebuilding both sessions, got %+v", result)
+	}
+}
+
+func TestDecideIdle_ShouldStoreTheDecisionOnThePause(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	sessionID, err := sqlite.CreateSession(Session{Branch: "main", StartTime: start})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	manualID, err := sqlite.PauseSession(sessionID, start.Add(5*time.Minute), PauseReasonLunch, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sqlite.ResumeSession(sessionID, start.Add(10*time.Minute)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	afkID, err := sqlite.PauseSession(sessionID, start.Add(15*time.Minute), PauseReasonAfk, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := sqlite.DecideIdle(afkID, start.Add(time.Hour), IdleKept, ""); !errors.Is(err, ErrNotIdlePause) {
+		t.Errorf("expected ErrNotIdlePause while still AFK, got %v", err)
+	}
+	if err := sqlite.ResumeSession(sessionID, start.Add(57*time.Minute)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	pauses, err := sqlite.ListPauses(sessionID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pauses[0].AwaitsDecision() || !pauses[1].AwaitsDecision() {
+		t.Errorf("expected only the ended AFK pause to await a decision, got %+v", pauses)
+	}
+
+	if err := sqlite.DecideIdle(manualID, start.Add(time.Hour), IdleKept, ""); !errors.Is(err, ErrNotIdlePause) {
+		t.Errorf("expected ErrNotIdlePause for a lunch break, got %v", err)
+	}
+	if err := sqlite.DecideIdle(42, start.Add(time.Hour), IdleKept, ""); !errors.Is(err, ErrPauseNotFound) {
+		t.Errorf("expected ErrPauseNotFound, got %v", err)
+	}
+	if err := sqlite.DecideIdle(afkID, start.Add(time.Hour), IdleKept, "whiteboard"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	pauses, err = sqlite.ListPauses(sessionID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if p := pauses[1]; !p.IsKept() || p.Activity != "whiteboard" || p.AwaitsDecision() {
+		t.Errorf("expected the AFK pause to be kept as whiteboard, got %+v", p)
+	}
+
+	var decidedAt time.Time
+	err = sqlite.(*sqliteDB).db.QueryRow(`SELECT at FROM events WHERE type = ? AND pause_id = ?`, eventIdleDecided, afkID).Scan(&decidedAt)
+	if err != nil || !decidedAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("expected the decision to be recorded at the given time, got %v (%v)", decidedAt, err)
+	}
+
+	// A locked screen is time away from the keyboard as well
+	lockID, err := sqlite.PauseSession(sessionID, start.Add(58*time.Minute), PauseReasonScreenLock, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sqlite.ResumeSession(sessionID, start.Add(59*time.Minute)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	pauses, err = sqlite.ListPauses(sessionID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !pauses[2].AwaitsDecision() {
+		t.Errorf("expected the screen lock to await a decision, got %+v", pauses[2])
+	}
+	if err := sqlite.DecideIdle(lockID, start.Add(time.Hour), IdleDiscarded, ""); err != nil {
+		t.Errorf("expected a screen lock to be decidable, got %v", err)
+	}
+
+	result, err := sqlite.RebuildFromEvents(true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Differences) != 0 {
+		t.Errorf("expected the decision to be in the event log, got %q", result.Differences)
+	}
+}
+
+func TestListPendingIdle_ShouldIncludeCompletedSessions(t *testing.T) {
+	sqlite := newTestDB(t)
+
+	start := time.Now().UTC().Add(-10 * 24 * time.Hour).Truncate(time.Second)
+	oldID, err := sqlite.CreateSession(Session{Branch: "old", StartTime: start})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := sqlite.PauseSession(oldID, start.Add(time.Hour), PauseReasonAfk, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sqlite.CompleteSession(oldID, start.Add(2*time.Hour), ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	start = time.Now().UTC().Add(-3 * time.Hour).Truncate(time.Second)
+	sessionID, err := sqlite.CreateSession(Session{Branch: "main", StartTime: start})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := sqlite.PauseSession(sessionID, start.Add(30*time.Minute), PauseReasonLunch, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sqlite.ResumeSession(sessionID, start.Add(time.Hour)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	afkID, err := sqlite.PauseSession(sessionID, start.Add(2*time.Hour), PauseReasonAfk, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// Completed while still away
+	if err := sqlite.CompleteSession(sessionID, start.Add(150*time.Minute), ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	pending, err := sqlite.ListPendingIdle(time.Now().Add(-7 * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != afkID {
+		t.Fatalf("expected only the recent AFK pause to await a decision, got %+v", pending)
+	}
+
+	if err := sqlite.DecideIdle(afkID, time.Now(), IdleKept, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	pending, err = sqlite.ListPendingIdle(time.Now().Add(-7 * 24 * time.Hour))
+	if err != nil || len(pending) != 0 {
+		t.Errorf("expected nothing to await a decision once kept, got %+v (%v)", pending, err)
+	}
+}
+
+func TestPauseDecisionMigration_ShouldSettleEndedAwayPauses(t *testing.T) {
+	conn := openTestConn(t)
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// Before the events too, so the log is seeded with the pauses
+	var before []Migration
+	for _, m := range migrations {
+		if m.Name == "events" {
+			break
+		}
+		before = append(before, m)
+	}
+	if _, err := runMigrations(conn, before); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = conn.Exec(`
+		INSERT INTO sessions (id, branch, ticket, start_time, end_time, end_commit, is_paused, is_afk) VALUES
+			(1, 'main', '', '2026-10-17 09:00:00+00:00', NULL, '', 1, 1);
+		INSERT INTO pauses (id, session_id, pause_start, pause_end, is_afk, reason, note) VALUES
+			(1, 1, '2026-10-17 10:00:00+00:00', '2026-10-17 10:30:00+00:00', 1, 'afk', ''),
+			(2, 1, '2026-10-17 11:00:00+00:00', NULL, 1, 'afk', ''),
+			(3, 1, '2026-10-17 10:40:00+00:00', '2026-10-17 10:50:00+00:00', 1, 'suspend', '');
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := runMigrations(conn, migrations); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sqlite := &sqliteDB{db: conn}
+	pauses, err := sqlite.ListPauses(1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pauses[0].Decision != IdleDiscarded || pauses[1].Decision != IdleDiscarded || pauses[2].Decision != IdleUndecided {
+		t.Errorf("expected the ended pauses to be discarded and the open one undecided, got %+v", pauses)
+	}
+	result, err := sqlite.RebuildFromEvents(true)
+	if err != nil || len(result.Differences) != 0 {
+		t.Errorf("expected the log to agree with the tables, got %+v (%v)", result, err)
+	}
+}
@@ -1,10 +1,18 @@
 package git
 
 import (
+	"fmt"
 	"os/exec"
 	"strings"
 )
 
+// Repository identifies a git repository independently of the branch that is
+// checked out. ID stays the same across clones and moves of the working tree.
+type Repository struct {
+	Root string
+	ID   string
+}
+
 func GetCurrentBranchName() (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
 	output, err := cmd.Output()
@@ -15,3 +23,97 @@ func GetCurrentBranchName() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetCurrentRepository returns the repository containing the working directory.
+func GetCurrentRepository() (Repository, error) {
+	root, err := run("", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return Repository{}, err
+	}
+
+	id, err := GetRepositoryID(root)
+	if err != nil {
+		return Repository{}, err
+	}
+
+	return Repository{Root: root, ID: id}, nil
+}
+
+// GetRepositoryID returns a stable identifier for the repository at dir: the
+// hash of its first commit, or the origin URL for a repository without
+// history yet, or its top-level path as a last resort.
+func GetRepositoryID(dir string) (string, error) {
+	roots, err := run(dir, "rev-list", "--max-parents=0", "HEAD")
+	if err == nil && roots != "" {
+		lines := strings.Split(roots, "\n")
+		return strings.TrimSpace(lines[len(lines)-1]), nil
+	}
+
+	if origin, err := run(dir, "config", "--get", "remote.origin.url"); err == nil && origin != "" {
+		return origin, nil
+	}
+
+	return run(dir, "rev-parse", "--show-toplevel")
+}
+
+// GetRepository returns the repository containing dir.
+func GetRepository(dir string) (Repository, error) {
+	root, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return Repository{}, err
+	}
+
+	id, err := GetRepositoryID(root)
+	if err != nil {
+		return Repository{}, err
+	}
+
+	return Repository{Root: root, ID: id}, nil
+}
+
+// GetBranchName returns the branch checked out in the repository at dir, or
+// "HEAD" while HEAD is detached.
+func GetBranchName(dir string) (string, error) {
+	return run(dir, "rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// GetGitDir returns the absolute path of the git directory of the repository
+// at dir, which is not <dir>/.git for worktrees and submodules.
+func GetGitDir(dir string) (string, error) {
+	return run(dir, "rev-parse", "--absolute-git-dir")
+}
+
+// Checkout checks out branch in the repository at dir. Git's own message is
+// returned as the error when it refuses, e.g. over uncommitted changes.
+func Checkout(dir, branch string) error {
+	args := []string{"checkout", "--quiet", branch}
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("git checkout %s: %s", branch, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetHeadCommit returns the commit hash HEAD points to in the repository at dir.
+func GetHeadCommit(dir string) (string, error) {
+	return run(dir, "rev-parse", "HEAD")
+}
+
+func run(dir string, args ...string) (string, error) {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,69 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=lofi", "-c", "user.email=lofi@example.com", "commit", "-q", "--allow-empty", "-m", "first"},
+		{"-c", "user.name=lofi", "-c", "user.email=lofi@example.com", "commit", "-q", "--allow-empty", "-m", "second"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	return dir
+}
+
+func TestGetRepositoryID_ShouldReturnFirstCommit(t *testing.T) {
+	dir := initTestRepo(t)
+
+	id, err := GetRepositoryID(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	first, err := run(dir, "rev-list", "--reverse", "HEAD")
+	if err != nil {
+		t.Fatalf("expected no error listing commits, got %v", err)
+	}
+	if first[:40] != id {
+		t.Errorf("expected repository id %s, got %s", first[:40], id)
+	}
+
+	head, err := GetHeadCommit(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if head == id {
+		t.Errorf("expected HEAD to differ from the first commit")
+	}
+}
+
+func TestGetRepositoryID_WhenRepositoryHasNoCommits_ShouldFallBackToOrigin(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"remote", "add", "origin", "git@example.com:team/project.git"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	id, err := GetRepositoryID(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if id != "git@example.com:team/project.git" {
+		t.Errorf("expected origin URL as id, got %s", id)
+	}
+}
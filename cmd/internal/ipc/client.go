@@ -0,0 +1,201 @@
+package ipc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+)
+
+// dialTimeout keeps the CLI snappy when a socket file is left without a
+// daemon behind it.
+const dialTimeout = time.Second
+
+var _ tracker.Tracker = (*Client)(nil)
+
+// Client is a Tracker backed by the daemon. Sessions it starts are recorded
+// against Repo.
+type Client struct {
+	Repo git.Repository
+
+	mu      sync.Mutex
+	conn    net.Conn
+	scanner *bufio.Scanner
+	nextID  int64
+}
+
+// Dial connects to the daemon's socket at path.
+func Dial(path string, repo git.Repository) (*Client, error) {
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &Client{Repo: repo, conn: conn, scanner: scanner}, nil
+}
+
+func (c *Client) call(method string, params, result any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	req := Request{JSONRPC: jsonrpcVersion, ID: c.nextID, Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = raw
+	}
+
+	if err := json.NewEncoder(c.conn).Encode(req); err != nil {
+		return err
+	}
+
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return err
+		}
+		return errors.New("daemon closed the connection")
+	}
+
+	var resp Response
+	if err := json.Unmarshal(c.scanner.Bytes(), &resp); err != nil {
+		return err
+	}
+	if resp.ID != req.ID {
+		return fmt.Errorf("daemon answered request %d, expected %d", resp.ID, req.ID)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result != nil {
+		return json.Unmarshal(resp.Result, result)
+	}
+	return nil
+}
+
+// Ping returns who is answering on the socket.
+func (c *Client) Ping() (PingResult, error) {
+	var result PingResult
+	err := c.call(MethodPing, nil, &result)
+	return result, err
+}
+
+// Start implements Tracker.
+func (c *Client) Start(branch string) error {
+	return c.call(MethodStart, StartParams{Branch: branch, Repo: c.Repo}, nil)
+}
+
+// Pause implements Tracker.
+func (c *Client) Pause(reason db.PauseReason, note string) error {
+	return c.call(MethodPause, PauseParams{Reason: reason, Note: note}, nil)
+}
+
+// PauseAt implements Tracker.
+func (c *Client) PauseAt(at time.Time, reason db.PauseReason, note string) error {
+	return c.call(MethodPause, PauseParams{Reason: reason, Note: note, At: &at}, nil)
+}
+
+// Resume implements Tracker.
+func (c *Client) Resume() error {
+	return c.call(MethodResume, nil, nil)
+}
+
+// ResumeFromAfk implements Tracker.
+func (c *Client) ResumeFromAfk() error {
+	return c.call(MethodResume, ResumeParams{Afk: true}, nil)
+}
+
+// DecideIdle implements Tracker.
+func (c *Client) DecideIdle(pauseID int64, decision db.IdleDecision, activity string) error {
+	return c.call(MethodIdle, IdleParams{PauseID: pauseID, Decision: decision, Activity: activity}, nil)
+}
+
+// PendingIdle implements Tracker.
+func (c *Client) PendingIdle() ([]db.Pause, error) {
+	var pauses []db.Pause
+	err := c.call(MethodPendingIdle, nil, &pauses)
+	return pauses, err
+}
+
+// Status implements Tracker.
+func (c *Client) Status() (tracker.SessionStatus, error) {
+	var status tracker.SessionStatus
+	err := c.call(MethodStatus, nil, &status)
+	return status, err
+}
+
+// Complete implements Tracker.
+func (c *Client) Complete() (tracker.SessionStatus, error) {
+	var status tracker.SessionStatus
+	err := c.call(MethodComplete, nil, &status)
+	return status, err
+}
+
+// Switch implements Tracker.
+func (c *Client) Switch(branch string) (tracker.SessionStatus, error) {
+	var status tracker.SessionStatus
+	err := c.call(MethodSwitch, StartParams{Branch: branch, Repo: c.Repo}, &status)
+	return status, err
+}
+
+// Undo implements Tracker.
+func (c *Client) Undo() (db.Operation, error) {
+	var op db.Operation
+	err := c.call(MethodUndo, nil, &op)
+	return op, err
+}
+
+// Subscribe turns the connection into an event stream. The channel is closed
+// when ctx is done or the daemon goes away; the client cannot make calls
+// afterwards.
+func (c *Client) Subscribe(ctx context.Context) (<-chan Event, error) {
+	if err := c.call(MethodSubscribe, nil, nil); err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		<-ctx.Done()
+		c.conn.Close()
+	}()
+	go func() {
+		defer close(events)
+		for c.scanner.Scan() {
+			var msg Response
+			if err := json.Unmarshal(c.scanner.Bytes(), &msg); err != nil || msg.Method != MethodEvent {
+				continue
+			}
+
+			var event Event
+			if err := json.Unmarshal(msg.Params, &event); err != nil {
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// Close implements Tracker. It closes the connection, the daemon keeps
+// running.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,269 @@
+package ipc
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+)
+
+var testRepo = git.Repository{ID: "4b825dc642cb6eb9a060e54bf8d69288fbee4904"}
+
+// newTestServer serves a tracker on a fresh database and returns the socket.
+func newTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	database, err := db.NewSQLiteDB(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatalf("expected no error opening database, got %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	server := &Server{
+		Tracker: tracker.NewTracker(git.Repository{}, database),
+		ForRepo: func(repo git.Repository) (tracker.Tracker, error) {
+			return tracker.NewTracker(repo, database), nil
+		},
+		Info: PingResult{PID: 42, DBPath: "/data/test.db"},
+	}
+
+	socketPath := filepath.Join(dir, "test.sock")
+	l, err := Listen(socketPath)
+	if err != nil {
+		t.Fatalf("expected no error listening, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go server.Serve(ctx, l)
+
+	return server, socketPath
+}
+
+func dial(t *testing.T, socketPath string) *Client {
+	t.Helper()
+
+	client, err := Dial(socketPath, testRepo)
+	if err != nil {
+		t.Fatalf("expected no error dialing, got %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestClient_ShouldDriveTheDaemonTracker(t *testing.T) {
+	_, socketPath := newTestServer(t)
+	client := dial(t, socketPath)
+
+	info, err := client.Ping()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if info.PID != 42 || info.DBPath != "/data/test.db" {
+		t.Errorf("expected the server info, got %+v", info)
+	}
+
+	if err := client.Start("feature/ABC-1-login"); err != nil {
+		t.Fatalf("expected no error starting, got %v", err)
+	}
+	if err := client.Pause(db.PauseReasonLunch, "pasta"); err != nil {
+		t.Fatalf("expected no error pausing, got %v", err)
+	}
+
+	status, err := client.Status()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.Branch != "feature/ABC-1-login" || status.Ticket != "ABC-1" || status.RepoID != testRepo.ID {
+		t.Errorf("expected the session started by the client, got %+v", status)
+	}
+	if !status.IsPaused || status.PauseReason != db.PauseReasonLunch {
+		t.Errorf("expected a lunch pause, got %+v", status)
+	}
+
+	if err := client.Resume(); err != nil {
+		t.Fatalf("expected no error resuming, got %v", err)
+	}
+	if _, err := client.Switch("feature/ABC-2-signup"); err != nil {
+		t.Fatalf("expected no error switching, got %v", err)
+	}
+	if _, err := client.Switch("feature/ABC-2-signup"); !errors.Is(err, tracker.ErrAlreadyOnBranch) {
+		t.Errorf("expected ErrAlreadyOnBranch, got %v", err)
+	}
+	if _, err := client.Complete(); err != nil {
+		t.Fatalf("expected no error completing, got %v", err)
+	}
+}
+
+func TestClient_ShouldReturnTrackerErrorsAsSentinels(t *testing.T) {
+	_, socketPath := newTestServer(t)
+	client := dial(t, socketPath)
+
+	if _, err := client.Status(); !errors.Is(err, db.ErrNoActiveSession) {
+		t.Errorf("expected ErrNoActiveSession, got %v", err)
+	}
+
+	if err := client.Start("main"); err != nil {
+		t.Fatalf("expected no error starting, got %v", err)
+	}
+	if err := client.Start("main"); !errors.Is(err, db.ErrActiveSessionAlreadyActive) {
+		t.Errorf("expected ErrActiveSessionAlreadyActive, got %v", err)
+	}
+	if err := client.Pause("not a reason", ""); !errors.Is(err, db.ErrInvalidPauseReason) {
+		t.Errorf("expected ErrInvalidPauseReason, got %v", err)
+	}
+	if err := client.Resume(); !errors.Is(err, tracker.ErrNotPaused) {
+		t.Errorf("expected ErrNotPaused, got %v", err)
+	}
+	if err := client.Pause(db.PauseReasonManual, ""); err != nil {
+		t.Fatalf("expected no error pausing, got %v", err)
+	}
+	if err := client.Pause(db.PauseReasonAfk, ""); !errors.Is(err, tracker.ErrAlreadyPaused) {
+		t.Errorf("expected ErrAlreadyPaused, got %v", err)
+	}
+	if err := client.ResumeFromAfk(); !errors.Is(err, tracker.ErrPausedManually) {
+		t.Errorf("expected ErrPausedManually, got %v", err)
+	}
+}
+
+func TestSubscribe_ShouldReceiveChangesFromClientsAndTheDaemon(t *testing.T) {
+	server, socketPath := newTestServer(t)
+	subscriber := dial(t, socketPath)
+	client := dial(t, socketPath)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := subscriber.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("expected no error subscribing, got %v", err)
+	}
+
+	if err := client.Start("main"); err != nil {
+		t.Fatalf("expected no error starting, got %v", err)
+	}
+	if err := server.Local().Pause(db.PauseReasonAfk, "idle for 15m"); err != nil {
+		t.Fatalf("expected no error pausing, got %v", err)
+	}
+	if err := client.Resume(); err != nil {
+		t.Fatalf("expected no error resuming, got %v", err)
+	}
+
+	expected := []EventType{EventStarted, EventPaused, EventResumed}
+	for _, want := range expected {
+		select {
+		case event := <-events:
+			if event.Type != want {
+				t.Errorf("expected event %s, got %s", want, event.Type)
+			}
+			if event.Status == nil || event.Status.Branch != "main" {
+				t.Errorf("expected the status of main with the event, got %+v", event.Status)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected event %s, got none", want)
+		}
+	}
+}
+
+func TestClient_PauseAt_ShouldBackdateOverTheSocket(t *testing.T) {
+	_, socketPath := newTestServer(t)
+	client := dial(t, socketPath)
+
+	if err := client.Start("main"); err != nil {
+		t.Fatalf("expected no error starting, got %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := client.PauseAt(time.Now().Add(-time.Hour), db.PauseReasonAfk, ""); err != nil {
+		t.Fatalf("expected no error pausing, got %v", err)
+	}
+
+	// Backdated to the start of the session, nothing was worked
+	status, err := client.Status()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !status.IsAfk || status.TotalDuration != 0 {
+		t.Errorf("expected an AFK pause since the start, got %+v", status)
+	}
+}
+
+func TestClient_DecideIdle_ShouldKeepAfkTimeOverTheSocket(t *testing.T) {
+	_, socketPath := newTestServer(t)
+	client := dial(t, socketPath)
+
+	if err := client.Start("main"); err != nil {
+		t.Fatalf("expected no error starting, got %v", err)
+	}
+	if err := client.PauseAt(time.Now().Add(-time.Hour), db.PauseReasonAfk, ""); err != nil {
+		t.Fatalf("expected no error pausing, got %v", err)
+	}
+	if err := client.ResumeFromAfk(); err != nil {
+		t.Fatalf("expected no error resuming, got %v", err)
+	}
+
+	pending, err := client.PendingIdle()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected the AFK pause to await a decision, got %+v", pending)
+	}
+	pauseID := pending[0].ID
+
+	if err := client.DecideIdle(pauseID, "maybe", ""); !errors.Is(err, db.ErrInvalidIdleDecision) {
+		t.Errorf("expected ErrInvalidIdleDecision, got %v", err)
+	}
+	if err := client.DecideIdle(pauseID+1, db.IdleKept, ""); !errors.Is(err, db.ErrPauseNotFound) {
+		t.Errorf("expected ErrPauseNotFound, got %v", err)
+	}
+	if err := client.DecideIdle(pauseID, db.IdleKept, "call"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if pending, err := client.PendingIdle(); err != nil || len(pending) != 0 {
+		t.Errorf("expected nothing to await a decision, got %+v (%v)", pending, err)
+	}
+	status, err := client.Status()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.AfkDuration != 0 || status.KeptByActivity["call"] <= 0 {
+		t.Errorf("expected the AFK time to be kept as a call, got %+v", status)
+	}
+}
+
+func TestClient_Undo_ShouldRevertOverTheSocket(t *testing.T) {
+	_, socketPath := newTestServer(t)
+	client := dial(t, socketPath)
+
+	if err := client.Start("main"); err != nil {
+		t.Fatalf("expected no error starting, got %v", err)
+	}
+	if _, err := client.Complete(); err != nil {
+		t.Fatalf("expected no error completing, got %v", err)
+	}
+
+	op, err := client.Undo()
+	if err != nil {
+		t.Fatalf("expected no error undoing, got %v", err)
+	}
+	if op.Kind != db.OpComplete || op.Branch != "main" {
+		t.Errorf("expected complete of main to be undone, got %+v", op)
+	}
+	if _, err := client.Status(); err != nil {
+		t.Errorf("expected the session to be active again, got %v", err)
+	}
+
+	if _, err := client.Undo(); err != nil {
+		t.Fatalf("expected no error undoing start, got %v", err)
+	}
+	if _, err := client.Undo(); !errors.Is(err, db.ErrNothingToUndo) {
+		t.Errorf("expected ErrNothingToUndo, got %v", err)
+	}
+}
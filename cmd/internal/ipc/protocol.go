@@ -0,0 +1,166 @@
+// Package ipc is the control socket between the CLI and the daemon. Messages
+// are JSON-RPC 2.0 objects, one per line, over a Unix domain socket.
+package ipc
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+)
+
+const jsonrpcVersion = "2.0"
+
+const (
+	MethodPing     = "ping"
+	MethodStatus   = "status"
+	MethodStart    = "start"
+	MethodPause    = "pause"
+	MethodResume   = "resume"
+	MethodComplete = "complete"
+	MethodSwitch   = "switch"
+	MethodUndo     = "undo"
+	MethodIdle     = "idle"
+	// MethodPendingIdle lists the idle time awaiting a decision
+	MethodPendingIdle = "pending_idle"
+	MethodSubscribe   = "subscribe"
+	// MethodEvent is the notification pushed to subscribers
+	MethodEvent = "event"
+)
+
+// Request is a call from the client. Every call gets exactly one Response.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response answers the Request with the same ID. On a subscribed connection
+// it is also used for event notifications, which set Method and Params and
+// carry no ID.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+type PingResult struct {
+	PID    int    `json:"pid"`
+	DBPath string `json:"db_path"`
+}
+
+// StartParams are the params of start and switch.
+type StartParams struct {
+	Branch string         `json:"branch"`
+	Repo   git.Repository `json:"repo"`
+}
+
+type PauseParams struct {
+	Reason db.PauseReason `json:"reason"`
+	Note   string         `json:"note,omitempty"`
+	// At backdates the pause, as the daemon does to the last input; left
+	// out the pause starts now
+	At *time.Time `json:"at,omitempty"`
+}
+
+// ResumeParams are the params of resume; they may be left out.
+type ResumeParams struct {
+	// Afk resumes only an AFK pause, as the daemon does once input is back
+	Afk bool `json:"afk,omitempty"`
+}
+
+// IdleParams are the params of idle, which keeps or discards AFK time.
+type IdleParams struct {
+	PauseID  int64           `json:"pause_id"`
+	Decision db.IdleDecision `json:"decision"`
+	Activity string          `json:"activity,omitempty"`
+}
+
+type EventType string
+
+const (
+	EventStarted   EventType = "started"
+	EventPaused    EventType = "paused"
+	EventResumed   EventType = "resumed"
+	EventCompleted EventType = "completed"
+	EventSwitched  EventType = "switched"
+	EventUndone    EventType = "undone"
+	EventDecided   EventType = "decided"
+)
+
+// Event is published to subscribers after every change of the tracked
+// session, whether it came from the CLI or from the daemon itself.
+type Event struct {
+	Type   EventType              `json:"type"`
+	Time   time.Time              `json:"time"`
+	Status *tracker.SessionStatus `json:"status,omitempty"`
+}
+
+// Error is a JSON-RPC error object. Codes below zero are protocol errors,
+// codes above zero carry the tracker's sentinel errors across the socket.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternal       = -32000
+
+	codeNoActiveSession    = 1
+	codeAlreadyActive      = 2
+	codeTrackingDisabled   = 3
+	codeInvalidPauseReason = 4
+	codeAlreadyOnBranch    = 5
+	codeNothingToUndo      = 6
+	codeCannotUndo         = 7
+	codeAlreadyPaused      = 8
+	codeNotPaused          = 9
+	codePausedManually     = 10
+	codePauseNotFound      = 11
+	codeNotIdlePause       = 12
+	codeInvalidDecision    = 13
+)
+
+var sentinels = map[int]error{
+	codeNoActiveSession:    db.ErrNoActiveSession,
+	codeAlreadyActive:      db.ErrActiveSessionAlreadyActive,
+	codeTrackingDisabled:   tracker.ErrTrackingDisabled,
+	codeInvalidPauseReason: db.ErrInvalidPauseReason,
+	codeAlreadyOnBranch:    tracker.ErrAlreadyOnBranch,
+	codeNothingToUndo:      db.ErrNothingToUndo,
+	codeCannotUndo:         db.ErrCannotUndo,
+	codeAlreadyPaused:      tracker.ErrAlreadyPaused,
+	codeNotPaused:          tracker.ErrNotPaused,
+	codePausedManually:     tracker.ErrPausedManually,
+	codePauseNotFound:      db.ErrPauseNotFound,
+	codeNotIdlePause:       db.ErrNotIdlePause,
+	codeInvalidDecision:    db.ErrInvalidIdleDecision,
+}
+
+func toError(err error) *Error {
+	for code, sentinel := range sentinels {
+		if errors.Is(err, sentinel) {
+			return &Error{Code: code, Message: err.Error()}
+		}
+	}
+	return &Error{Code: codeInternal, Message: err.Error()}
+}
+
+// Unwrap lets errors.Is match the sentinel an error code stands for.
+func (e *Error) Unwrap() error {
+	return sentinels[e.Code]
+}
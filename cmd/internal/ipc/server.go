@@ -0,0 +1,435 @@
+package ipc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+)
+
+// subscriberBuffer is how many events a slow subscriber may lag behind before
+// events are dropped for it.
+const subscriberBuffer = 16
+
+// Server answers control requests with the daemon's tracker. All calls, from
+// clients and from the daemon itself through Local, are serialized so the
+// session is only ever changed by one caller at a time.
+type Server struct {
+	// Tracker serves every method but start
+	Tracker tracker.Tracker
+	// ForRepo returns the tracker that starts sessions in repo, configured for
+	// that repository. The returned tracker must not own the database
+	// connection, it is never closed.
+	ForRepo func(repo git.Repository) (tracker.Tracker, error)
+	// Info is returned by ping
+	Info PingResult
+
+	mu          sync.Mutex
+	subMu       sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+// Listen listens on the Unix socket at path, replacing a leftover socket
+// file. Only the owner may connect.
+func Listen(path string) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Serve accepts connections on l until ctx is done.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		go s.handle(ctx, conn)
+	}
+}
+
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	enc := json.NewEncoder(conn)
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			enc.Encode(Response{JSONRPC: jsonrpcVersion, Error: &Error{Code: codeParseError, Message: err.Error()}})
+			continue
+		}
+
+		if req.Method == MethodSubscribe {
+			// Subscribe before answering, so no change made after the
+			// client saw the answer is missed.
+			events := s.subscribe()
+			defer s.unsubscribe(events)
+
+			enc.Encode(Response{JSONRPC: jsonrpcVersion, ID: req.ID, Result: json.RawMessage("true")})
+			s.stream(ctx, conn, enc, events)
+			return
+		}
+
+		resp := Response{JSONRPC: jsonrpcVersion, ID: req.ID}
+		result, err := s.dispatch(req)
+		if err != nil {
+			var rpcErr *Error
+			if !errors.As(err, &rpcErr) {
+				rpcErr = toError(err)
+			}
+			resp.Error = rpcErr
+		} else {
+			resp.Result, err = json.Marshal(result)
+			if err != nil {
+				resp.Error = toError(err)
+			}
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+// stream pushes events to a subscribed connection until either side hangs up.
+func (s *Server) stream(ctx context.Context, conn net.Conn, enc *json.Encoder, events chan Event) {
+	// The client sends nothing after subscribing, a read returns once it
+	// closes the connection.
+	closed := make(chan struct{})
+	go func() {
+		conn.Read(make([]byte, 1))
+		close(closed)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		case event := <-events:
+			params, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if err := enc.Encode(Response{JSONRPC: jsonrpcVersion, Method: MethodEvent, Params: params}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) dispatch(req Request) (any, error) {
+	switch req.Method {
+	case MethodPing:
+		return s.Info, nil
+	case MethodStatus:
+		return s.Local().Status()
+	case MethodStart:
+		var params StartParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &Error{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return true, s.StartIn(params.Repo, params.Branch)
+	case MethodPause:
+		var params PauseParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &Error{Code: codeInvalidParams, Message: err.Error()}
+		}
+		reason, err := db.ParsePauseReason(string(params.Reason))
+		if err != nil {
+			return nil, err
+		}
+		if params.At != nil {
+			return true, s.Local().PauseAt(*params.At, reason, params.Note)
+		}
+		return true, s.Local().Pause(reason, params.Note)
+	case MethodResume:
+		var params ResumeParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return nil, &Error{Code: codeInvalidParams, Message: err.Error()}
+			}
+		}
+		if params.Afk {
+			return true, s.Local().ResumeFromAfk()
+		}
+		return true, s.Local().Resume()
+	case MethodComplete:
+		return s.Local().Complete()
+	case MethodSwitch:
+		var params StartParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &Error{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.SwitchIn(params.Repo, params.Branch)
+	case MethodUndo:
+		return s.Local().Undo()
+	case MethodIdle:
+		var params IdleParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &Error{Code: codeInvalidParams, Message: err.Error()}
+		}
+		decision, err := db.ParseIdleDecision(string(params.Decision))
+		if err != nil {
+			return nil, err
+		}
+		return true, s.Local().DecideIdle(params.PauseID, decision, params.Activity)
+	case MethodPendingIdle:
+		return s.Local().PendingIdle()
+	default:
+		return nil, &Error{Code: codeMethodNotFound, Message: fmt.Sprintf("unknown method %q", req.Method)}
+	}
+}
+
+// StartIn starts a session on branch in repo, as a start request would.
+func (s *Server) StartIn(repo git.Repository, branch string) error {
+	tr, err := s.trackerFor(repo)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := tr.Start(branch); err != nil {
+		return err
+	}
+	s.publishStatus(EventStarted)
+	return nil
+}
+
+// SwitchIn moves tracking to branch in repo, as a switch request would.
+func (s *Server) SwitchIn(repo git.Repository, branch string) (tracker.SessionStatus, error) {
+	tr, err := s.trackerFor(repo)
+	if err != nil {
+		return tracker.SessionStatus{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, err := tr.Switch(branch)
+	if err != nil {
+		return status, err
+	}
+	s.publishStatus(EventSwitched)
+	return status, nil
+}
+
+func (s *Server) trackerFor(repo git.Repository) (tracker.Tracker, error) {
+	if s.ForRepo == nil {
+		return s.Tracker, nil
+	}
+	return s.ForRepo(repo)
+}
+
+// Local returns a Tracker for use inside the daemon that goes through the
+// same lock and publishes the same events as requests over the socket.
+func (s *Server) Local() tracker.Tracker {
+	return localTracker{s}
+}
+
+type localTracker struct {
+	s *Server
+}
+
+func (l localTracker) Start(branch string) error {
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+
+	if err := l.s.Tracker.Start(branch); err != nil {
+		return err
+	}
+	l.s.publishStatus(EventStarted)
+	return nil
+}
+
+func (l localTracker) Pause(reason db.PauseReason, note string) error {
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+
+	if err := l.s.Tracker.Pause(reason, note); err != nil {
+		return err
+	}
+	l.s.publishStatus(EventPaused)
+	return nil
+}
+
+func (l localTracker) PauseAt(at time.Time, reason db.PauseReason, note string) error {
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+
+	if err := l.s.Tracker.PauseAt(at, reason, note); err != nil {
+		return err
+	}
+	l.s.publishStatus(EventPaused)
+	return nil
+}
+
+func (l localTracker) Resume() error {
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+
+	if err := l.s.Tracker.Resume(); err != nil {
+		return err
+	}
+	l.s.publishStatus(EventResumed)
+	return nil
+}
+
+func (l localTracker) ResumeFromAfk() error {
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+
+	if err := l.s.Tracker.ResumeFromAfk(); err != nil {
+		return err
+	}
+	l.s.publishStatus(EventResumed)
+	return nil
+}
+
+func (l localTracker) DecideIdle(pauseID int64, decision db.IdleDecision, activity string) error {
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+
+	if err := l.s.Tracker.DecideIdle(pauseID, decision, activity); err != nil {
+		return err
+	}
+	l.s.publishStatus(EventDecided)
+	return nil
+}
+
+func (l localTracker) PendingIdle() ([]db.Pause, error) {
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+
+	return l.s.Tracker.PendingIdle()
+}
+
+func (l localTracker) Status() (tracker.SessionStatus, error) {
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+
+	return l.s.Tracker.Status()
+}
+
+func (l localTracker) Complete() (tracker.SessionStatus, error) {
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+
+	status, err := l.s.Tracker.Complete()
+	if err != nil {
+		return status, err
+	}
+	l.s.publish(Event{Type: EventCompleted, Time: time.Now().UTC(), Status: &status})
+	return status, nil
+}
+
+func (l localTracker) Switch(branch string) (tracker.SessionStatus, error) {
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+
+	status, err := l.s.Tracker.Switch(branch)
+	if err != nil {
+		return status, err
+	}
+	l.s.publishStatus(EventSwitched)
+	return status, nil
+}
+
+func (l localTracker) Undo() (db.Operation, error) {
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+
+	op, err := l.s.Tracker.Undo()
+	if err != nil {
+		return op, err
+	}
+	l.s.publishStatus(EventUndone)
+	return op, nil
+}
+
+// Close is a no-op, the daemon owns the tracker.
+func (l localTracker) Close() error {
+	return nil
+}
+
+// publishStatus publishes an event with the status after a change. It must
+// be called with mu held.
+func (s *Server) publishStatus(eventType EventType) {
+	event := Event{Type: eventType, Time: time.Now().UTC()}
+	if status, err := s.Tracker.Status(); err == nil {
+		event.Status = &status
+	}
+	s.publish(event)
+}
+
+func (s *Server) publish(event Event) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns the events published from now on for use inside the
+// daemon, and a function to stop receiving them.
+func (s *Server) Subscribe() (<-chan Event, func()) {
+	ch := s.subscribe()
+	return ch, func() { s.unsubscribe(ch) }
+}
+
+func (s *Server) subscribe() chan Event {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	if s.subscribers == nil {
+		s.subscribers = map[chan Event]struct{}{}
+	}
+	ch := make(chan Event, subscriberBuffer)
+	s.subscribers[ch] = struct{}{}
+	return ch
+}
+
+func (s *Server) unsubscribe(ch chan Event) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	delete(s.subscribers, ch)
+}
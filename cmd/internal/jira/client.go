@@ -0,0 +1,153 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// startedFormat is the timestamp layout the Jira REST API expects.
+const startedFormat = "2006-01-02T15:04:05.000-0700"
+
+// worklogPageSize is how many worklogs Worklogs asks for at a time. Jira may
+// return fewer.
+const worklogPageSize = 1000
+
+// Worklog is a unit of time logged against an issue.
+type Worklog struct {
+	ID        string
+	Started   time.Time
+	TimeSpent time.Duration
+	Comment   string
+}
+
+// Client talks to the Jira REST API (v2). With an Email it authenticates with
+// basic auth and an API token as used by Jira Cloud, otherwise it sends the
+// token as a bearer personal access token as used by Jira Server.
+type Client struct {
+	BaseURL  string
+	Email    string
+	APIToken string
+	HTTP     *http.Client
+}
+
+func NewClient(baseURL, email, apiToken string) *Client {
+	return &Client{
+		BaseURL:  strings.TrimRight(baseURL, "/"),
+		Email:    email,
+		APIToken: apiToken,
+		HTTP:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type worklogPayload struct {
+	ID               string `json:"id,omitempty"`
+	Started          string `json:"started"`
+	TimeSpentSeconds int    `json:"timeSpentSeconds"`
+	Comment          string `json:"comment,omitempty"`
+}
+
+// AddWorklog logs w on the issue and returns the ID Jira assigned to it.
+func (c *Client) AddWorklog(ctx context.Context, issueKey string, w Worklog) (string, error) {
+	payload := worklogPayload{
+		Started:          w.Started.Format(startedFormat),
+		TimeSpentSeconds: int(w.TimeSpent / time.Second),
+		Comment:          w.Comment,
+	}
+
+	var created worklogPayload
+	if err := c.do(ctx, http.MethodPost, worklogPath(issueKey), payload, &created); err != nil {
+		return "", err
+	}
+	if created.ID == "" {
+		return "", fmt.Errorf("jira returned no worklog id for %s", issueKey)
+	}
+
+	return created.ID, nil
+}
+
+// Worklogs lists the worklogs of an issue, following Jira's pages until all
+// of them are read.
+func (c *Client) Worklogs(ctx context.Context, issueKey string) ([]Worklog, error) {
+	var worklogs []Worklog
+	for {
+		var page struct {
+			Total    int              `json:"total"`
+			Worklogs []worklogPayload `json:"worklogs"`
+		}
+		query := url.Values{
+			"startAt":    {strconv.Itoa(len(worklogs))},
+			"maxResults": {strconv.Itoa(worklogPageSize)},
+		}
+		if err := c.do(ctx, http.MethodGet, worklogPath(issueKey)+"?"+query.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+
+		for _, p := range page.Worklogs {
+			started, _ := time.Parse(startedFormat, p.Started)
+			worklogs = append(worklogs, Worklog{
+				ID:        p.ID,
+				Started:   started,
+				TimeSpent: time.Duration(p.TimeSpentSeconds) * time.Second,
+				Comment:   p.Comment,
+			})
+		}
+
+		// An empty page ends the loop too, should total be off
+		if len(page.Worklogs) == 0 || len(worklogs) >= page.Total {
+			return worklogs, nil
+		}
+	}
+}
+
+func worklogPath(issueKey string) string {
+	return "/rest/api/2/issue/" + url.PathEscape(issueKey) + "/worklog"
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Email != "" {
+		req.SetBasicAuth(c.Email, c.APIToken)
+	} else if c.APIToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIToken)
+	}
+
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("jira %s %s: %s: %s", method, path, res.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
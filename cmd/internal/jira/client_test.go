@@ -0,0 +1,37 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestWorklogs_WhenJiraPages_ShouldReadEveryPage(t *testing.T) {
+	fake, server := newFakeJira(t)
+	fake.pageSize = 2
+	for i := 0; i < 3; i++ {
+		fake.worklogs["ABC-1"] = append(fake.worklogs["ABC-1"], worklogPayload{
+			ID:               fmt.Sprint(100 + i),
+			Started:          "2026-10-12T09:00:00.000+0000",
+			TimeSpentSeconds: 900,
+		})
+	}
+
+	client := NewClient(server.URL, "dev@example.com", "secret")
+	worklogs, err := client.Worklogs(context.Background(), "ABC-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(worklogs) != 3 {
+		t.Fatalf("expected 3 worklogs, got %d", len(worklogs))
+	}
+	for i, w := range worklogs {
+		if w.ID != fmt.Sprint(100+i) {
+			t.Errorf("expected worklog %d to be %d, got %s", i, 100+i, w.ID)
+		}
+	}
+	if fake.gets != 2 {
+		t.Errorf("expected 2 pages to be fetched, got %d", fake.gets)
+	}
+}
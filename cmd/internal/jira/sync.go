@@ -0,0 +1,218 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/report"
+)
+
+// RoundMode says in which direction worked time is rounded.
+type RoundMode string
+
+const (
+	RoundUp      RoundMode = "up"
+	RoundNearest RoundMode = "nearest"
+	RoundDown    RoundMode = "down"
+)
+
+// ParseRoundMode validates a rounding mode name.
+func ParseRoundMode(s string) (RoundMode, error) {
+	switch mode := RoundMode(strings.ToLower(s)); mode {
+	case RoundUp, RoundNearest, RoundDown:
+		return mode, nil
+	case "":
+		return RoundUp, nil
+	}
+	return "", fmt.Errorf("unknown rounding mode %q, use up, nearest or down", s)
+}
+
+// Rules turn the worked time of a session into the time that is logged.
+type Rules struct {
+	// RoundTo rounds logged time to a multiple of this, zero disables rounding
+	RoundTo time.Duration
+	Mode    RoundMode
+	// MinDuration skips sessions whose worked time is shorter than this
+	MinDuration time.Duration
+}
+
+// Apply returns the time to log for worked, or zero when nothing is logged.
+// Jira refuses worklogs shorter than a minute, so those are never logged.
+func (r Rules) Apply(worked time.Duration) time.Duration {
+	if worked < r.MinDuration {
+		return 0
+	}
+
+	logged := worked
+	if r.RoundTo > 0 {
+		switch r.Mode {
+		case RoundDown:
+			logged = worked.Truncate(r.RoundTo)
+		case RoundNearest:
+			logged = worked.Round(r.RoundTo)
+		default:
+			logged = worked.Truncate(r.RoundTo)
+			if logged < worked {
+				logged += r.RoundTo
+			}
+		}
+	}
+
+	logged = logged.Truncate(time.Minute)
+	if logged < time.Minute {
+		return 0
+	}
+	return logged
+}
+
+// Store is the part of db.DB the syncer needs.
+type Store interface {
+	ListSessions(filter db.SessionFilter) ([]db.Session, error)
+	RecordWorklog(worklog db.Worklog) error
+}
+
+// WorklogClient is the part of the Jira API the syncer needs.
+type WorklogClient interface {
+	AddWorklog(ctx context.Context, issueKey string, w Worklog) (string, error)
+	Worklogs(ctx context.Context, issueKey string) ([]Worklog, error)
+}
+
+// Result describes what happened to one session during a sync.
+type Result struct {
+	Session   db.Session
+	IssueKey  string
+	TimeSpent time.Duration
+	WorklogID string
+	// Skipped explains why the session was not logged, if it was not
+	Skipped string
+	// Recovered is set when the worklog already existed in Jira, e.g. because
+	// an earlier run was interrupted before it could record it locally
+	Recovered bool
+	Err       error
+}
+
+// Syncer pushes completed sessions to Jira as worklogs.
+type Syncer struct {
+	Store  Store
+	Client WorklogClient
+	Rules  Rules
+	// IssueKey returns the issue a session is logged on, "" to skip it
+	IssueKey func(s db.Session) string
+}
+
+// Sync logs every completed session that has not been synced yet. With
+// dryRun nothing is sent to Jira or recorded. Failures of single sessions are
+// reported in their Result and do not stop the others.
+func (s *Syncer) Sync(ctx context.Context, dryRun bool) ([]Result, error) {
+	sessions, err := s.Store.ListSessions(db.SessionFilter{
+		State:    db.CompletedSessions,
+		Unsynced: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(sessions))
+	for _, session := range sessions {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		result := Result{Session: session, IssueKey: s.IssueKey(session)}
+		if result.IssueKey == "" {
+			result.Skipped = "no ticket in branch name"
+			result.Err = s.recordSkip(result, dryRun)
+			results = append(results, result)
+			continue
+		}
+
+		// Not recorded: deciding the idle time changes what is logged, and the
+		// session is to be synced once it is decided
+		if awaitsIdleDecision(session) {
+			result.Skipped = "idle time awaits a decision, keep or discard it with 'lofi-tracker idle'"
+			results = append(results, result)
+			continue
+		}
+
+		result.TimeSpent = s.Rules.Apply(report.Worked(session, *session.Endtime))
+		if result.TimeSpent == 0 {
+			result.Skipped = "below minimum duration"
+			result.Err = s.recordSkip(result, dryRun)
+			results = append(results, result)
+			continue
+		}
+
+		if !dryRun {
+			result.WorklogID, result.Recovered, result.Err = s.push(ctx, session, result.IssueKey, result.TimeSpent)
+			if result.Err == nil {
+				result.Err = s.Store.RecordWorklog(db.Worklog{
+					SessionID: session.ID,
+					IssueKey:  result.IssueKey,
+					WorklogID: result.WorklogID,
+					Seconds:   int(result.TimeSpent / time.Second),
+					SyncedAt:  time.Now().UTC(),
+				})
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// recordSkip remembers that the session of result is not logged, so later
+// runs leave it alone. Editing the session makes it a candidate again.
+func (s *Syncer) recordSkip(result Result, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	return s.Store.RecordWorklog(db.Worklog{
+		SessionID: result.Session.ID,
+		IssueKey:  result.IssueKey,
+		SyncedAt:  time.Now().UTC(),
+		Skipped:   result.Skipped,
+	})
+}
+
+// awaitsIdleDecision reports whether the session has AFK, suspend or screen
+// lock time that is neither kept nor discarded yet.
+func awaitsIdleDecision(session db.Session) bool {
+	for _, p := range session.Pauses {
+		if p.AwaitsDecision() {
+			return true
+		}
+	}
+	return false
+}
+
+// push creates the worklog unless one carrying the session's marker exists.
+func (s *Syncer) push(ctx context.Context, session db.Session, issueKey string, spent time.Duration) (string, bool, error) {
+	marker := sessionMarker(session)
+
+	existing, err := s.Client.Worklogs(ctx, issueKey)
+	if err != nil {
+		return "", false, err
+	}
+	for _, w := range existing {
+		if strings.Contains(w.Comment, marker) {
+			return w.ID, true, nil
+		}
+	}
+
+	id, err := s.Client.AddWorklog(ctx, issueKey, Worklog{
+		Started:   session.StartTime,
+		TimeSpent: spent,
+		Comment:   fmt.Sprintf("Worked on %s (%s)", session.Branch, marker),
+	})
+	return id, false, err
+}
+
+// sessionMarker identifies a session in worklog comments. The start time
+// keeps markers of different people's databases apart.
+func sessionMarker(session db.Session) string {
+	return fmt.Sprintf("lofi-tracker#%d@%d", session.ID, session.StartTime.Unix())
+}
@@ -0,0 +1,351 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+)
+
+// fakeJira is a local stand-in for the Jira worklog endpoints.
+type fakeJira struct {
+	mu       sync.Mutex
+	worklogs map[string][]worklogPayload
+	posts    int
+	auth     []string
+	// pageSize caps how many worklogs a GET returns, like Jira does
+	pageSize int
+	gets     int
+}
+
+func newFakeJira(t *testing.T) (*fakeJira, *httptest.Server) {
+	f := &fakeJira{worklogs: map[string][]worklogPayload{}}
+	server := httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeJira) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+
+	key, ok := strings.CutPrefix(r.URL.Path, "/rest/api/2/issue/")
+	key, ok2 := strings.CutSuffix(key, "/worklog")
+	if !ok || !ok2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		f.gets++
+		all := f.worklogs[key]
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		end := len(all)
+		if f.pageSize > 0 {
+			end = min(end, startAt+f.pageSize)
+		}
+		page := []worklogPayload{}
+		if startAt < end {
+			page = all[startAt:end]
+		}
+		json.NewEncoder(w).Encode(map[string]any{"startAt": startAt, "total": len(all), "worklogs": page})
+	case http.MethodPost:
+		var payload worklogPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := time.Parse(startedFormat, payload.Started); err != nil {
+			http.Error(w, "bad started", http.StatusBadRequest)
+			return
+		}
+		f.posts++
+		payload.ID = fmt.Sprint(10000 + f.posts)
+		f.worklogs[key] = append(f.worklogs[key], payload)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(payload)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestStore(t *testing.T) db.DB {
+	t.Helper()
+
+	store, err := db.NewSQLiteDB(filepath.Join(t.TempDir(), "lofi-tracker.db"))
+	if err != nil {
+		t.Fatalf("expected no error opening database, got %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func addSession(t *testing.T, store db.DB, branch, ticket string, start time.Time, worked time.Duration) int64 {
+	t.Helper()
+
+	id, err := store.CreateSession(db.Session{Branch: branch, Ticket: ticket, StartTime: start})
+	if err != nil {
+		t.Fatalf("expected no error creating session, got %v", err)
+	}
+	if err := store.CompleteSession(id, start.Add(worked), ""); err != nil {
+		t.Fatalf("expected no error completing session, got %v", err)
+	}
+	return id
+}
+
+func newTestSyncer(store db.DB, serverURL string) *Syncer {
+	return &Syncer{
+		Store:    store,
+		Client:   NewClient(serverURL+"/", "dev@example.com", "secret"),
+		Rules:    Rules{RoundTo: 15 * time.Minute, Mode: RoundUp, MinDuration: 5 * time.Minute},
+		IssueKey: func(s db.Session) string { return s.Ticket },
+	}
+}
+
+func TestSync_ShouldPushCompletedSessionsOnce(t *testing.T) {
+	fake, server := newFakeJira(t)
+	store := newTestStore(t)
+
+	start := time.Date(2026, time.October, 12, 9, 0, 0, 0, time.UTC)
+	addSession(t, store, "feature/ABC-1", "ABC-1", start, 52*time.Minute)
+	addSession(t, store, "main", "", start.Add(time.Hour), time.Hour)
+	addSession(t, store, "feature/ABC-2", "ABC-2", start.Add(2*time.Hour), 3*time.Minute)
+	if _, err := store.CreateSession(db.Session{Branch: "feature/ABC-3", Ticket: "ABC-3", StartTime: start.Add(3 * time.Hour)}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	syncer := newTestSyncer(store, server.URL)
+	results, err := syncer.Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected the three completed sessions, got %d", len(results))
+	}
+	if results[0].Err != nil || results[0].WorklogID != "10001" || results[0].TimeSpent != time.Hour {
+		t.Errorf("expected ABC-1 to be logged as 1h with id 10001, got %+v", results[0])
+	}
+	if results[1].Skipped == "" || results[2].Skipped == "" {
+		t.Errorf("expected the session without ticket and the short one to be skipped, got %+v / %+v", results[1], results[2])
+	}
+
+	logged := fake.worklogs["ABC-1"]
+	if len(logged) != 1 || logged[0].TimeSpentSeconds != 3600 || logged[0].Started != "2026-10-12T09:00:00.000+0000" {
+		t.Errorf("unexpected worklog payload %+v", logged)
+	}
+	if fake.auth[0] == "" || !strings.HasPrefix(fake.auth[0], "Basic ") {
+		t.Errorf("expected basic auth, got %q", fake.auth[0])
+	}
+
+	again, err := syncer.Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if fake.posts != 1 {
+		t.Errorf("expected re-running to post nothing new, got %d posts", fake.posts)
+	}
+	for _, r := range again {
+		if r.Session.Ticket == "ABC-1" {
+			t.Errorf("expected the synced session not to be listed again")
+		}
+	}
+}
+
+func TestSync_WhenSessionWasSkipped_ShouldNotReportItAgain(t *testing.T) {
+	_, server := newFakeJira(t)
+	store := newTestStore(t)
+
+	start := time.Date(2026, time.October, 12, 9, 0, 0, 0, time.UTC)
+	addSession(t, store, "main", "", start, time.Hour)
+	shortID := addSession(t, store, "feature/ABC-2", "ABC-2", start.Add(2*time.Hour), 3*time.Minute)
+
+	syncer := newTestSyncer(store, server.URL)
+	results, err := syncer.Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(results) != 2 || results[0].Skipped == "" || results[1].Skipped == "" || results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("expected both sessions to be skipped, got %+v", results)
+	}
+
+	again, err := syncer.Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("expected the skipped sessions not to be reported again, got %+v", again)
+	}
+
+	// Editing a skipped session offers it again
+	short, err := store.GetSession(shortID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	end := short.StartTime.Add(time.Hour)
+	short.Endtime = &end
+	if err := store.UpdateSession(*short); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	again, err = syncer.Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(again) != 1 || again[0].Session.ID != shortID || again[0].WorklogID == "" {
+		t.Errorf("expected the edited session to be logged, got %+v", again)
+	}
+}
+
+func TestSync_WhenIdleTimeIsUndecided_ShouldWaitForTheDecision(t *testing.T) {
+	fake, server := newFakeJira(t)
+	store := newTestStore(t)
+
+	start := time.Date(2026, time.October, 12, 9, 0, 0, 0, time.UTC)
+	id, err := store.CreateSession(db.Session{Branch: "feature/ABC-1", Ticket: "ABC-1", StartTime: start})
+	if err != nil {
+		t.Fatalf("expected no error creating session, got %v", err)
+	}
+	pauseID, err := store.PauseSession(id, start.Add(30*time.Minute), db.PauseReasonSuspend, "")
+	if err != nil {
+		t.Fatalf("expected no error pausing session, got %v", err)
+	}
+	if err := store.ResumeSession(id, start.Add(time.Hour)); err != nil {
+		t.Fatalf("expected no error resuming session, got %v", err)
+	}
+	if err := store.CompleteSession(id, start.Add(90*time.Minute), ""); err != nil {
+		t.Fatalf("expected no error completing session, got %v", err)
+	}
+
+	syncer := newTestSyncer(store, server.URL)
+	for run := 1; run <= 2; run++ {
+		results, err := syncer.Sync(context.Background(), false)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(results) != 1 || results[0].Skipped == "" || results[0].Err != nil {
+			t.Fatalf("expected run %d to skip the session, got %+v", run, results)
+		}
+	}
+	if fake.posts != 0 {
+		t.Errorf("expected nothing to be posted before the decision, got %d posts", fake.posts)
+	}
+
+	if err := store.DecideIdle(pauseID, start.Add(2*time.Hour), db.IdleKept, ""); err != nil {
+		t.Fatalf("expected no error deciding idle time, got %v", err)
+	}
+	results, err := syncer.Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(results) != 1 || results[0].WorklogID == "" || results[0].TimeSpent != 90*time.Minute {
+		t.Errorf("expected the session to be logged with the kept idle time as 1h30m, got %+v", results)
+	}
+}
+
+func TestSync_WithDryRun_ShouldNotPushOrRecord(t *testing.T) {
+	fake, server := newFakeJira(t)
+	store := newTestStore(t)
+
+	addSession(t, store, "feature/ABC-1", "ABC-1", time.Date(2026, time.October, 12, 9, 0, 0, 0, time.UTC), time.Hour)
+
+	syncer := newTestSyncer(store, server.URL)
+	results, err := syncer.Sync(context.Background(), true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(results) != 1 || results[0].TimeSpent != time.Hour || results[0].WorklogID != "" {
+		t.Errorf("expected a planned 1h worklog, got %+v", results)
+	}
+	if len(fake.auth) != 0 {
+		t.Errorf("expected no requests in dry-run mode, got %d", len(fake.auth))
+	}
+
+	pending, _ := store.ListSessions(db.SessionFilter{Unsynced: true})
+	if len(pending) != 1 {
+		t.Errorf("expected the session to stay unsynced, got %d", len(pending))
+	}
+}
+
+func TestSync_WhenWorklogAlreadyExists_ShouldRecoverIt(t *testing.T) {
+	fake, server := newFakeJira(t)
+	store := newTestStore(t)
+
+	start := time.Date(2026, time.October, 12, 9, 0, 0, 0, time.UTC)
+	id := addSession(t, store, "feature/ABC-1", "ABC-1", start, time.Hour)
+	fake.worklogs["ABC-1"] = []worklogPayload{{
+		ID:      "777",
+		Started: start.Format(startedFormat),
+		Comment: fmt.Sprintf("Worked on feature/ABC-1 (lofi-tracker#%d@%d)", id, start.Unix()),
+	}}
+
+	results, err := newTestSyncer(store, server.URL).Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(results) != 1 || !results[0].Recovered || results[0].WorklogID != "777" {
+		t.Errorf("expected the existing worklog to be recovered, got %+v", results)
+	}
+	if fake.posts != 0 {
+		t.Errorf("expected no new worklog, got %d posts", fake.posts)
+	}
+}
+
+func TestSync_WhenJiraFails_ShouldReportAndKeepSessionUnsynced(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errorMessages":["Issue does not exist"]}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+	store := newTestStore(t)
+
+	addSession(t, store, "feature/NOPE-1", "NOPE-1", time.Date(2026, time.October, 12, 9, 0, 0, 0, time.UTC), time.Hour)
+
+	results, err := newTestSyncer(store, server.URL).Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(results) != 1 || results[0].Err == nil || !strings.Contains(results[0].Err.Error(), "Issue does not exist") {
+		t.Errorf("expected the Jira error to be reported, got %+v", results)
+	}
+
+	pending, _ := store.ListSessions(db.SessionFilter{Unsynced: true})
+	if len(pending) != 1 {
+		t.Errorf("expected the session to stay unsynced, got %d", len(pending))
+	}
+}
+
+func TestRules_Apply(t *testing.T) {
+	tests := []struct {
+		rules  Rules
+		worked time.Duration
+		want   time.Duration
+	}{
+		{rules: Rules{}, worked: 61*time.Minute + 30*time.Second, want: 61 * time.Minute},
+		{rules: Rules{RoundTo: 15 * time.Minute, Mode: RoundUp}, worked: 61 * time.Minute, want: 75 * time.Minute},
+		{rules: Rules{RoundTo: 15 * time.Minute, Mode: RoundNearest}, worked: 67 * time.Minute, want: 60 * time.Minute},
+		{rules: Rules{RoundTo: 15 * time.Minute, Mode: RoundDown}, worked: 74 * time.Minute, want: 60 * time.Minute},
+		{rules: Rules{RoundTo: 15 * time.Minute, Mode: RoundDown}, worked: 14 * time.Minute, want: 0},
+		{rules: Rules{MinDuration: 10 * time.Minute}, worked: 9 * time.Minute, want: 0},
+		{rules: Rules{}, worked: 45 * time.Second, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rules.Apply(tt.worked); got != tt.want {
+			t.Errorf("%+v.Apply(%v) = %v, want %v", tt.rules, tt.worked, got, tt.want)
+		}
+	}
+}
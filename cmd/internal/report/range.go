@@ -0,0 +1,74 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/weekday"
+)
+
+// Range is a half-open time range [From, To). Ranges built by this package
+// start and end at local midnight in the location of the reference time.
+type Range struct {
+	From time.Time
+	To   time.Time
+}
+
+// DayRange returns the calendar day containing t.
+func DayRange(t time.Time) Range {
+	start := midnight(t)
+	return Range{From: start, To: start.AddDate(0, 0, 1)}
+}
+
+// WeekRange returns the week containing t, starting on weekStart.
+func WeekRange(t time.Time, weekStart time.Weekday) Range {
+	start := midnight(t)
+	offset := (int(start.Weekday()) - int(weekStart) + 7) % 7
+	start = start.AddDate(0, 0, -offset)
+	return Range{From: start, To: start.AddDate(0, 0, 7)}
+}
+
+// MonthRange returns the calendar month containing t.
+func MonthRange(t time.Time) Range {
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return Range{From: start, To: start.AddDate(0, 1, 0)}
+}
+
+// DatesRange returns the range from the start of from to the end of to, so
+// both dates are included.
+func DatesRange(from, to time.Time) (Range, error) {
+	r := Range{From: midnight(from), To: midnight(to).AddDate(0, 0, 1)}
+	if !r.To.After(r.From) {
+		return Range{}, fmt.Errorf("range end %s is before its start %s", to.Format(time.DateOnly), from.Format(time.DateOnly))
+	}
+	return r, nil
+}
+
+// ParseDay parses a day relative to now: "today", "yesterday", a weekday name
+// for the most recent such day, or a YYYY-MM-DD date. It returns local
+// midnight of that day.
+func ParseDay(s string, now time.Time) (time.Time, error) {
+	today := midnight(now)
+	switch name := strings.ToLower(strings.TrimSpace(s)); name {
+	case "", "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	if day, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		return day, nil
+	}
+
+	d, err := weekday.Parse(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unknown day %q, use today, yesterday, a weekday or YYYY-MM-DD", s)
+	}
+	back := (int(today.Weekday()) - int(d) + 7) % 7
+	return today.AddDate(0, 0, -back), nil
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
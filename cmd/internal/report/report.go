@@ -0,0 +1,278 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+)
+
+// Entry is the worked time of one group of sessions: a branch of one
+// repository, or a ticket, project or client across branches and
+// repositories.
+type Entry struct {
+	Name     string
+	RepoPath string
+	RepoID   string
+	Duration time.Duration
+	// Manual is the part of Duration that was entered afterwards rather
+	// than tracked live
+	Manual time.Duration
+}
+
+// GroupBy returns the Entry a session's time is aggregated under, with zero
+// durations.
+type GroupBy func(s db.Session) Entry
+
+// ByBranch groups sessions by repository and branch.
+func ByBranch(s db.Session) Entry {
+	return Entry{Name: s.Branch, RepoPath: s.RepoPath, RepoID: s.RepoID}
+}
+
+// ByTicket groups sessions by the ticket key returned by ticketOf, no matter
+// which branch or repository the time was tracked on.
+func ByTicket(ticketOf func(s db.Session) string) GroupBy {
+	return func(s db.Session) Entry {
+		return Entry{Name: ticketOf(s)}
+	}
+}
+
+// ByProject groups sessions by client and project name, as configured for the
+// repository when they were started.
+func ByProject(s db.Session) Entry {
+	name := s.Project
+	if name == "" {
+		name = "(no project)"
+	}
+	if s.Client != "" {
+		name = s.Client + " / " + name
+	}
+	return Entry{Name: name}
+}
+
+// ByClient groups sessions by client name.
+func ByClient(s db.Session) Entry {
+	if s.Client == "" {
+		return Entry{Name: "(no client)"}
+	}
+	return Entry{Name: s.Client}
+}
+
+// Day is the worked time on a single local calendar day.
+type Day struct {
+	Date     time.Time
+	Duration time.Duration
+	Manual   time.Duration
+	Entries  []Entry
+}
+
+type Report struct {
+	Range Range
+	Total time.Duration
+	// Manual is the part of Total that was entered afterwards
+	Manual         time.Duration
+	Paused         time.Duration
+	PausedByReason map[db.PauseReason]time.Duration
+	// Entries is sorted by worked time, longest first
+	Entries []Entry
+	// Days holds only days with worked time, oldest first
+	Days []Day
+}
+
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+// Build aggregates the pause-adjusted worked time of sessions inside r. Open
+// sessions and pauses count up to now, sessions crossing the range bounds are
+// clipped, and time is attributed to days in the location of r.From.
+func Build(sessions []db.Session, r Range, now time.Time, groupBy GroupBy) Report {
+	rep := Report{
+		Range:          r,
+		PausedByReason: map[db.PauseReason]time.Duration{},
+	}
+
+	entries := map[Entry]*Entry{}
+	days := map[time.Time]map[Entry]*Entry{}
+
+	for _, s := range sessions {
+		k := groupBy(s)
+		work, pauses := split(s, now)
+		manual := s.Origin == db.OriginManual
+
+		for _, p := range pauses {
+			d := clip(p.interval, r).duration()
+			if d <= 0 {
+				continue
+			}
+			rep.Paused += d
+			rep.PausedByReason[p.reason] += d
+		}
+
+		for _, w := range work {
+			for _, part := range splitDays(clip(w, r), r.From.Location()) {
+				d := part.duration()
+				if d <= 0 {
+					continue
+				}
+				var m time.Duration
+				if manual {
+					m = d
+				}
+				rep.Total += d
+				rep.Manual += m
+
+				if entries[k] == nil {
+					entry := k
+					entries[k] = &entry
+				}
+				entries[k].Duration += d
+				entries[k].Manual += m
+
+				date := midnight(part.start.In(r.From.Location()))
+				if days[date] == nil {
+					days[date] = map[Entry]*Entry{}
+				}
+				if days[date][k] == nil {
+					entry := k
+					days[date][k] = &entry
+				}
+				days[date][k].Duration += d
+				days[date][k].Manual += m
+			}
+		}
+	}
+
+	for _, e := range entries {
+		rep.Entries = append(rep.Entries, *e)
+	}
+	sortEntries(rep.Entries)
+
+	for date, dayEntries := range days {
+		day := Day{Date: date}
+		for _, e := range dayEntries {
+			day.Duration += e.Duration
+			day.Manual += e.Manual
+			day.Entries = append(day.Entries, *e)
+		}
+		sortEntries(day.Entries)
+		rep.Days = append(rep.Days, day)
+	}
+	sort.Slice(rep.Days, func(i, j int) bool {
+		return rep.Days[i].Date.Before(rep.Days[j].Date)
+	})
+
+	return rep
+}
+
+// Worked returns the worked time of a session, its pauses subtracted. An open
+// session counts up to now.
+func Worked(s db.Session, now time.Time) time.Duration {
+	work, _ := split(s, now)
+
+	var total time.Duration
+	for _, w := range work {
+		total += w.duration()
+	}
+	return total
+}
+
+type pauseInterval struct {
+	interval
+	reason db.PauseReason
+}
+
+// split cuts a session into its worked intervals and its pauses, with every
+// pause clipped to the session bounds.
+func split(s db.Session, now time.Time) ([]interval, []pauseInterval) {
+	end := now
+	if s.Endtime != nil {
+		end = *s.Endtime
+	}
+
+	var pauses []pauseInterval
+	for _, p := range s.Pauses {
+		// Idle time kept as work is no pause at all
+		if p.IsKept() {
+			continue
+		}
+		pauseEnd := end
+		if p.PauseEnd != nil && p.PauseEnd.Before(end) {
+			pauseEnd = *p.PauseEnd
+		}
+		pauseStart := p.PauseStart
+		if pauseStart.Before(s.StartTime) {
+			pauseStart = s.StartTime
+		}
+		if !pauseEnd.After(pauseStart) {
+			continue
+		}
+
+		reason := p.Reason
+		if reason == "" {
+			reason = db.PauseReasonManual
+			if p.IsAfk {
+				reason = db.PauseReasonAfk
+			}
+		}
+		pauses = append(pauses, pauseInterval{interval: interval{pauseStart, pauseEnd}, reason: reason})
+	}
+	sort.Slice(pauses, func(i, j int) bool {
+		return pauses[i].start.Before(pauses[j].start)
+	})
+
+	var work []interval
+	cursor := s.StartTime
+	for _, p := range pauses {
+		if p.start.After(cursor) {
+			work = append(work, interval{cursor, p.start})
+		}
+		if p.end.After(cursor) {
+			cursor = p.end
+		}
+	}
+	if end.After(cursor) {
+		work = append(work, interval{cursor, end})
+	}
+
+	return work, pauses
+}
+
+func clip(i interval, r Range) interval {
+	if i.start.Before(r.From) {
+		i.start = r.From
+	}
+	if i.end.After(r.To) {
+		i.end = r.To
+	}
+	return i
+}
+
+// splitDays cuts an interval at every local midnight it crosses.
+func splitDays(i interval, loc *time.Location) []interval {
+	var parts []interval
+	for i.duration() > 0 {
+		next := midnight(i.start.In(loc)).AddDate(0, 0, 1)
+		if !next.Before(i.end) {
+			parts = append(parts, i)
+			break
+		}
+		parts = append(parts, interval{i.start, next})
+		i.start = next
+	}
+	return parts
+}
+
+func (i interval) duration() time.Duration {
+	return i.end.Sub(i.start)
+}
+
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Duration != entries[j].Duration {
+			return entries[i].Duration > entries[j].Duration
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
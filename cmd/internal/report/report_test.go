@@ -0,0 +1,191 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+)
+
+func at(loc *time.Location, day, hour, minute int) time.Time {
+	return time.Date(2026, time.October, day, hour, minute, 0, 0, loc)
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestWeekRange_ShouldRespectWeekStart(t *testing.T) {
+	saturday := at(time.UTC, 17, 15, 0)
+
+	monday := WeekRange(saturday, time.Monday)
+	if !monday.From.Equal(at(time.UTC, 12, 0, 0)) || !monday.To.Equal(at(time.UTC, 19, 0, 0)) {
+		t.Errorf("expected Monday week 12th-19th, got %v - %v", monday.From, monday.To)
+	}
+
+	sunday := WeekRange(saturday, time.Sunday)
+	if !sunday.From.Equal(at(time.UTC, 11, 0, 0)) || !sunday.To.Equal(at(time.UTC, 18, 0, 0)) {
+		t.Errorf("expected Sunday week 11th-18th, got %v - %v", sunday.From, sunday.To)
+	}
+}
+
+func TestBuild_ShouldSubtractPausesAndGroupByBranch(t *testing.T) {
+	loc := time.UTC
+	sessions := []db.Session{
+		{
+			ID: 1, Branch: "feature/ABC-1", RepoID: "repo",
+			StartTime: at(loc, 12, 9, 0), Endtime: ptr(at(loc, 12, 13, 0)),
+			Pauses: []db.Pause{
+				{PauseStart: at(loc, 12, 10, 0), PauseEnd: ptr(at(loc, 12, 10, 30)), Reason: db.PauseReasonMeeting},
+				{PauseStart: at(loc, 12, 11, 0), PauseEnd: ptr(at(loc, 12, 12, 0)), Reason: db.PauseReasonAfk, IsAfk: true},
+			},
+		},
+		{
+			ID: 2, Branch: "main", RepoID: "repo",
+			StartTime: at(loc, 13, 9, 0), Endtime: ptr(at(loc, 13, 10, 0)),
+		},
+	}
+
+	rep := Build(sessions, WeekRange(at(loc, 14, 0, 0), time.Monday), at(loc, 18, 0, 0), ByBranch)
+
+	if rep.Total != 3*time.Hour+30*time.Minute {
+		t.Errorf("expected 3h30m worked, got %v", rep.Total)
+	}
+	if rep.Paused != 90*time.Minute || rep.PausedByReason[db.PauseReasonAfk] != time.Hour || rep.PausedByReason[db.PauseReasonMeeting] != 30*time.Minute {
+		t.Errorf("expected 1h AFK and 30m meeting, got %v (%v)", rep.Paused, rep.PausedByReason)
+	}
+	if len(rep.Entries) != 2 || rep.Entries[0].Name != "feature/ABC-1" || rep.Entries[0].Duration != 150*time.Minute {
+		t.Errorf("expected feature/ABC-1 first with 2h30m, got %+v", rep.Entries)
+	}
+	if len(rep.Days) != 2 || !rep.Days[0].Date.Equal(at(loc, 12, 0, 0)) || rep.Days[1].Duration != time.Hour {
+		t.Errorf("expected two days, got %+v", rep.Days)
+	}
+}
+
+func TestBuild_ShouldSplitAtLocalMidnightAndClipToRange(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*60*60)
+	// 22:00 to 02:00 local time, stored in UTC like the tracker does
+	sessions := []db.Session{
+		{
+			ID: 1, Branch: "hotfix",
+			StartTime: at(loc, 12, 22, 0).UTC(), Endtime: ptr(at(loc, 13, 2, 0).UTC()),
+		},
+	}
+
+	week := Build(sessions, WeekRange(at(loc, 12, 12, 0), time.Monday), at(loc, 18, 0, 0), ByBranch)
+	if len(week.Days) != 2 || week.Days[0].Duration != 2*time.Hour || week.Days[1].Duration != 2*time.Hour {
+		t.Fatalf("expected 2h on each side of midnight, got %+v", week.Days)
+	}
+
+	day := Build(sessions, DayRange(at(loc, 13, 12, 0)), at(loc, 18, 0, 0), ByBranch)
+	if day.Total != 2*time.Hour || len(day.Days) != 1 {
+		t.Errorf("expected only the 2h after midnight, got %v over %d days", day.Total, len(day.Days))
+	}
+}
+
+func TestBuild_WhenSessionIsOpen_ShouldCountUntilNow(t *testing.T) {
+	loc := time.UTC
+	sessions := []db.Session{
+		{
+			ID: 1, Branch: "main", StartTime: at(loc, 12, 9, 0), IsPaused: true,
+			Pauses: []db.Pause{{PauseStart: at(loc, 12, 10, 0), Reason: db.PauseReasonLunch}},
+		},
+	}
+
+	rep := Build(sessions, DayRange(at(loc, 12, 0, 0)), at(loc, 12, 11, 0), ByBranch)
+	if rep.Total != time.Hour || rep.PausedByReason[db.PauseReasonLunch] != time.Hour {
+		t.Errorf("expected 1h worked and 1h open lunch, got %v and %v", rep.Total, rep.PausedByReason)
+	}
+}
+
+func TestBuild_ShouldCountKeptIdleTimeAsWork(t *testing.T) {
+	loc := time.UTC
+	sessions := []db.Session{
+		{
+			ID: 1, Branch: "main", StartTime: at(loc, 12, 9, 0), Endtime: ptr(at(loc, 12, 12, 0)),
+			Pauses: []db.Pause{
+				{PauseStart: at(loc, 12, 10, 0), PauseEnd: ptr(at(loc, 12, 10, 45)), Reason: db.PauseReasonAfk, IsAfk: true, Decision: db.IdleKept, Activity: "whiteboard"},
+				{PauseStart: at(loc, 12, 11, 0), PauseEnd: ptr(at(loc, 12, 11, 30)), Reason: db.PauseReasonAfk, IsAfk: true, Decision: db.IdleDiscarded},
+			},
+		},
+	}
+
+	rep := Build(sessions, DayRange(at(loc, 12, 0, 0)), at(loc, 18, 0, 0), ByBranch)
+	if rep.Total != 150*time.Minute || rep.PausedByReason[db.PauseReasonAfk] != 30*time.Minute {
+		t.Errorf("expected 2h30m worked and 30m AFK, got %v and %v", rep.Total, rep.PausedByReason)
+	}
+}
+
+func TestBuild_ByTicket_ShouldMergeBranchesOfTheSameTicket(t *testing.T) {
+	loc := time.UTC
+	sessions := []db.Session{
+		{ID: 1, Branch: "feature/ABC-123", Ticket: "ABC-123", RepoID: "web", StartTime: at(loc, 12, 9, 0), Endtime: ptr(at(loc, 12, 10, 0))},
+		{ID: 2, Branch: "bugfix/ABC-123-followup", Ticket: "ABC-123", RepoID: "api", StartTime: at(loc, 12, 10, 0), Endtime: ptr(at(loc, 12, 10, 30))},
+		{ID: 3, Branch: "main", RepoID: "web", StartTime: at(loc, 12, 11, 0), Endtime: ptr(at(loc, 12, 11, 15))},
+	}
+
+	byTicket := ByTicket(func(s db.Session) string {
+		if s.Ticket == "" {
+			return "(no ticket)"
+		}
+		return s.Ticket
+	})
+	rep := Build(sessions, DayRange(at(loc, 12, 0, 0)), at(loc, 13, 0, 0), byTicket)
+
+	if len(rep.Entries) != 2 {
+		t.Fatalf("expected two tickets, got %+v", rep.Entries)
+	}
+	if rep.Entries[0].Name != "ABC-123" || rep.Entries[0].Duration != 90*time.Minute {
+		t.Errorf("expected 1h30m on ABC-123, got %+v", rep.Entries[0])
+	}
+	if rep.Entries[1].Name != "(no ticket)" || rep.Entries[1].Duration != 15*time.Minute {
+		t.Errorf("expected 15m without a ticket, got %+v", rep.Entries[1])
+	}
+}
+
+func TestBuild_ShouldSeparateManualTime(t *testing.T) {
+	loc := time.UTC
+	sessions := []db.Session{
+		{ID: 1, Branch: "main", StartTime: at(loc, 12, 9, 0), Endtime: ptr(at(loc, 12, 10, 0))},
+		{ID: 2, Branch: "main", Origin: db.OriginManual, StartTime: at(loc, 12, 10, 0), Endtime: ptr(at(loc, 12, 10, 30))},
+	}
+
+	rep := Build(sessions, DayRange(at(loc, 12, 0, 0)), at(loc, 13, 0, 0), ByBranch)
+
+	if rep.Total != 90*time.Minute || rep.Manual != 30*time.Minute {
+		t.Errorf("expected 1h30m with 30m manual, got %v with %v manual", rep.Total, rep.Manual)
+	}
+	if len(rep.Entries) != 1 || rep.Entries[0].Manual != 30*time.Minute {
+		t.Errorf("expected 30m manual on main, got %+v", rep.Entries)
+	}
+	if len(rep.Days) != 1 || rep.Days[0].Manual != 30*time.Minute {
+		t.Errorf("expected 30m manual on the day, got %+v", rep.Days)
+	}
+}
+
+func TestParseDay(t *testing.T) {
+	now := at(time.UTC, 15, 14, 30) // a Thursday
+
+	cases := map[string]time.Time{
+		"":           at(time.UTC, 15, 0, 0),
+		"today":      at(time.UTC, 15, 0, 0),
+		"yesterday":  at(time.UTC, 14, 0, 0),
+		"2026-10-01": at(time.UTC, 1, 0, 0),
+		"monday":     at(time.UTC, 12, 0, 0),
+		"Thursday":   at(time.UTC, 15, 0, 0),
+	}
+	for in, want := range cases {
+		got, err := ParseDay(in, now)
+		if err != nil {
+			t.Errorf("ParseDay(%q): expected no error, got %v", in, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseDay(%q): expected %v, got %v", in, want, got)
+		}
+	}
+
+	if _, err := ParseDay("someday", now); err == nil {
+		t.Errorf("expected an unknown day to fail")
+	}
+}
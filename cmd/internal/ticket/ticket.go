@@ -0,0 +1,80 @@
+package ticket
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultPattern matches Jira style issue keys such as ABC-123.
+const DefaultPattern = `[A-Z][A-Z0-9]+-[0-9]+`
+
+// DefaultFallback is the bucket for branches that do not name a ticket.
+const DefaultFallback = "(no ticket)"
+
+// Extractor derives ticket keys from branch names. Patterns are tried in
+// order; a pattern with a capture group named "ticket", or otherwise its
+// first group, yields that group, else the whole match is the key.
+type Extractor struct {
+	patterns []*regexp.Regexp
+	fallback string
+}
+
+// NewExtractor compiles patterns into an Extractor. Without patterns the
+// DefaultPattern is used, and without a fallback the DefaultFallback.
+func NewExtractor(patterns []string, fallback string) (*Extractor, error) {
+	if len(patterns) == 0 {
+		patterns = []string{DefaultPattern}
+	}
+	if fallback == "" {
+		fallback = DefaultFallback
+	}
+
+	e := &Extractor{fallback: fallback}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ticket pattern %q: %w", p, err)
+		}
+		e.patterns = append(e.patterns, re)
+	}
+
+	return e, nil
+}
+
+// Default returns an Extractor for Jira style keys.
+func Default() *Extractor {
+	e, _ := NewExtractor(nil, "")
+	return e
+}
+
+// Extract returns the ticket key named by branch, upper-cased, and whether
+// one was found.
+func (e *Extractor) Extract(branch string) (string, bool) {
+	for _, re := range e.patterns {
+		match := re.FindStringSubmatch(branch)
+		if match == nil {
+			continue
+		}
+
+		key := match[0]
+		if i := re.SubexpIndex("ticket"); i > 0 {
+			key = match[i]
+		} else if len(match) > 1 {
+			key = match[1]
+		}
+		if key != "" {
+			return strings.ToUpper(key), true
+		}
+	}
+
+	return "", false
+}
+
+// Bucket returns ticket, or the fallback bucket when there is none.
+func (e *Extractor) Bucket(ticket string) string {
+	if ticket == "" {
+		return e.fallback
+	}
+	return ticket
+}
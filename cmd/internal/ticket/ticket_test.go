@@ -0,0 +1,48 @@
+package ticket
+
+import "testing"
+
+func TestExtract_WithDefaultPattern(t *testing.T) {
+	tests := []struct {
+		branch string
+		want   string
+		found  bool
+	}{
+		{branch: "feature/ABC-123-fix-login", want: "ABC-123", found: true},
+		{branch: "bugfix/ABC-123-followup", want: "ABC-123", found: true},
+		{branch: "OPS2-7", want: "OPS2-7", found: true},
+		{branch: "main", found: false},
+		{branch: "feature/fix-2", found: false},
+	}
+
+	e := Default()
+	for _, tt := range tests {
+		got, found := e.Extract(tt.branch)
+		if got != tt.want || found != tt.found {
+			t.Errorf("Extract(%q) = %q, %v; want %q, %v", tt.branch, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestExtract_WithCustomPatterns(t *testing.T) {
+	e, err := NewExtractor([]string{`^(?i)(?:feature|bugfix)/(?P<ticket>[a-z]+-[0-9]+)`, `#([0-9]+)`}, "unplanned")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got, _ := e.Extract("feature/web-42-dark-mode"); got != "WEB-42" {
+		t.Errorf("expected WEB-42 from the named group, got %q", got)
+	}
+	if got, _ := e.Extract("hotfix/#991"); got != "991" {
+		t.Errorf("expected 991 from the second pattern, got %q", got)
+	}
+	if got := e.Bucket(""); got != "unplanned" {
+		t.Errorf("expected the configured bucket, got %q", got)
+	}
+}
+
+func TestNewExtractor_WhenPatternIsInvalid_ShouldFail(t *testing.T) {
+	if _, err := NewExtractor([]string{`([A-Z`}, ""); err == nil {
+		t.Errorf("expected an error for an invalid pattern")
+	}
+}
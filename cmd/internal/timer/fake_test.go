@@ -0,0 +1,93 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+func TestFake_Advance_ShouldMoveNow(t *testing.T) {
+	clock := NewFake(testStart)
+
+	clock.Advance(90 * time.Minute)
+
+	if want := testStart.Add(90 * time.Minute); !clock.Now().Equal(want) {
+		t.Errorf("expected %v, got %v", want, clock.Now())
+	}
+}
+
+func TestFake_Advance_ShouldFireDueTickers(t *testing.T) {
+	clock := NewFake(testStart)
+	ticker := clock.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	clock.Advance(59 * time.Second)
+	select {
+	case tick := <-ticker.C():
+		t.Fatalf("expected no tick yet, got %v", tick)
+	default:
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case tick := <-ticker.C():
+		if want := testStart.Add(time.Minute); !tick.Equal(want) {
+			t.Errorf("expected tick at %v, got %v", want, tick)
+		}
+	default:
+		t.Fatalf("expected a tick after a minute, got none")
+	}
+}
+
+func TestFake_Advance_WhenTickIsNotReceived_ShouldDropLaterTicks(t *testing.T) {
+	clock := NewFake(testStart)
+	ticker := clock.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	clock.Advance(5 * time.Minute)
+
+	<-ticker.C()
+	select {
+	case tick := <-ticker.C():
+		t.Fatalf("expected a single tick, got another at %v", tick)
+	default:
+	}
+
+	clock.Advance(time.Minute)
+	if tick := <-ticker.C(); !tick.Equal(testStart.Add(6 * time.Minute)) {
+		t.Errorf("expected the next tick on schedule, got %v", tick)
+	}
+}
+
+func TestFake_Stop_ShouldStopTicks(t *testing.T) {
+	clock := NewFake(testStart)
+	ticker := clock.NewTicker(time.Minute)
+
+	ticker.Stop()
+	clock.Advance(time.Hour)
+
+	select {
+	case tick := <-ticker.C():
+		t.Errorf("expected no tick after Stop, got %v", tick)
+	default:
+	}
+}
+
+func TestFake_WaitForTickers_ShouldWaitForAGoroutine(t *testing.T) {
+	clock := NewFake(testStart)
+	ticks := make(chan time.Time)
+
+	go func() {
+		ticker := clock.NewTicker(time.Second)
+		defer ticker.Stop()
+		ticks <- <-ticker.C()
+	}()
+
+	clock.WaitForTickers(1)
+	clock.Advance(time.Second)
+
+	if tick := <-ticks; !tick.Equal(testStart.Add(time.Second)) {
+		t.Errorf("expected tick at %v, got %v", testStart.Add(time.Second), tick)
+	}
+}
@@ -1,6 +1,10 @@
 package tracker
 
 import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/impactj90/lofi-tracker/cmd/internal/db"
@@ -8,47 +12,252 @@ import (
 
 type mockDB struct {
 	ActiveSession *db.Session
-	Paused        bool
-	IsAfk         bool
+	// Completed holds the sessions ended by SwitchSession
+	Completed []db.Session
+	Pauses    []db.Pause
+	Worklogs  []db.Worklog
+	Paused    bool
+	IsAfk     bool
 
-	CreateSessionCalled bool
-	PauseSessionCalled  bool
-	ResumeSessionCalled bool
+	CreateSessionCalled   bool
+	CompleteSessionCalled bool
+	SwitchSessionCalled   bool
+	PauseSessionCalled    bool
+	ResumeSessionCalled   bool
+	UndoCalled            bool
 }
 
-func (m *mockDB) CreateSession(branch string, startTime time.Time) (int64, error) {
+func (m *mockDB) CreateSession(session db.Session) (int64, error) {
 	m.CreateSessionCalled = true
-	m.ActiveSession = &db.Session{
-		ID:        1,
-		Branch:    branch,
-		StartTime: startTime,
-		IsPaused:  false,
-	}
+	session.ID = 1
+	session.IsPaused = false
+	m.ActiveSession = &session
 	return 1, nil
 }
 
-func (m *mockDB) CompleteSession(sessionID int64, endTime time.Time) error {
+func (m *mockDB) AddSession(session db.Session) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockDB) CompleteSession(sessionID int64, endTime time.Time, endCommit string) error {
+	m.CompleteSessionCalled = true
+	if m.ActiveSession != nil && m.ActiveSession.ID == sessionID {
+		m.ActiveSession.Endtime = &endTime
+		m.ActiveSession.EndCommit = endCommit
+	}
+	for i := range m.Pauses {
+		if m.Pauses[i].SessionID == sessionID && m.Pauses[i].PauseEnd == nil {
+			m.Pauses[i].PauseEnd = &endTime
+		}
+	}
 	return nil
 }
 
+func (m *mockDB) SwitchSession(sessionID int64, at time.Time, endCommit string, next db.Session) (int64, error) {
+	m.SwitchSessionCalled = true
+	if err := m.CompleteSession(sessionID, at, endCommit); err != nil {
+		return 0, err
+	}
+	m.Completed = append(m.Completed, *m.ActiveSession)
+	next.ID = sessionID + 1
+	next.StartTime = at
+	m.ActiveSession = &next
+	return next.ID, nil
+}
+
 func (m *mockDB) GetActiveSession() (*db.Session, error) {
-	if m.ActiveSession == nil {
+	if m.ActiveSession == nil || m.ActiveSession.Endtime != nil {
 		return nil, db.ErrNoActiveSession
 	}
 	return m.ActiveSession, nil
 }
 
-func (m *mockDB) PauseSession(sessionID int64, pauseStart time.Time, isAfk bool) (int64, error) {
+func (m *mockDB) GetSession(sessionID int64) (*db.Session, error) {
+	if m.ActiveSession == nil || m.ActiveSession.ID != sessionID {
+		return nil, db.ErrSessionNotFound
+	}
+	return m.ActiveSession, nil
+}
+
+func (m *mockDB) UpdateSession(session db.Session) error {
+	return nil
+}
+
+func (m *mockDB) DeleteSession(sessionID int64) error {
+	return nil
+}
+
+func (m *mockDB) ListAudit(sessionID int64) ([]db.AuditEntry, error) {
+	return nil, nil
+}
+
+func (m *mockDB) ListOperations(limit int) ([]db.Operation, error) {
+	return nil, nil
+}
+
+func (m *mockDB) UndoLastOperation() (db.Operation, error) {
+	m.UndoCalled = true
+	return db.Operation{}, db.ErrNothingToUndo
+}
+
+func (m *mockDB) RebuildFromEvents(dryRun bool) (db.RebuildResult, error) {
+	return db.RebuildResult{}, nil
+}
+
+// ListSessions applies filter to the completed sessions and the active one,
+// as the SQLite implementation does.
+func (m *mockDB) ListSessions(filter db.SessionFilter) ([]db.Session, error) {
+	all := append([]db.Session(nil), m.Completed...)
+	if m.ActiveSession != nil && !slices.ContainsFunc(all, func(s db.Session) bool { return s.ID == m.ActiveSession.ID }) {
+		all = append(all, *m.ActiveSession)
+	}
+
+	var sessions []db.Session
+	for _, session := range all {
+		if m.matches(session, filter) {
+			session.Pauses, _ = m.ListPauses(session.ID)
+			sessions = append(sessions, session)
+		}
+	}
+
+	slices.SortStableFunc(sessions, func(a, b db.Session) int {
+		if c := a.StartTime.Compare(b.StartTime); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	if filter.Order == db.NewestFirst {
+		slices.Reverse(sessions)
+	}
+
+	sessions = sessions[min(filter.Offset, len(sessions)):]
+	if filter.Limit > 0 && filter.Limit < len(sessions) {
+		sessions = sessions[:filter.Limit]
+	}
+	return sessions, nil
+}
+
+func (m *mockDB) matches(s db.Session, filter db.SessionFilter) bool {
+	completed := s.Endtime != nil
+	switch {
+	case !filter.To.IsZero() && !s.StartTime.Before(filter.To):
+		return false
+	case !filter.From.IsZero() && completed && !s.Endtime.After(filter.From):
+		return false
+	case filter.Branch != "" && !globMatch(filter.Branch, s.Branch):
+		return false
+	case filter.Ticket != "" && s.Ticket != filter.Ticket:
+		return false
+	case filter.Client != "" && s.Client != filter.Client:
+		return false
+	case filter.Project != "" && s.Project != filter.Project:
+		return false
+	case filter.Repo != "" && s.RepoID != filter.Repo && s.RepoPath != filter.Repo:
+		return false
+	case filter.Tag != "" && !slices.Contains(s.Tags, filter.Tag):
+		return false
+	case filter.Unsynced && slices.ContainsFunc(m.Worklogs, func(w db.Worklog) bool { return w.SessionID == s.ID }):
+		return false
+	case filter.State == db.ActiveSessions && completed, filter.State == db.CompletedSessions && !completed:
+		return false
+	}
+	return true
+}
+
+// globMatch matches like SQLite's GLOB, where * also spans slashes.
+func globMatch(pattern, s string) bool {
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			re.WriteString(".*")
+		case '?':
+			re.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				re.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+end]
+			re.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+
+	matched, err := regexp.MatchString(re.String(), s)
+	return err == nil && matched
+}
+
+func (m *mockDB) PauseSession(sessionID int64, pauseStart time.Time, reason db.PauseReason, note string) (int64, error) {
+	isAfk := reason.IsAfk()
 	m.Paused = true
+	m.IsAfk = isAfk
 	m.PauseSessionCalled = true
 	m.ActiveSession.IsPaused = true
-	return 1, nil
+	m.ActiveSession.IsAfk = isAfk
+	m.Pauses = append(m.Pauses, db.Pause{
+		ID:         int64(len(m.Pauses) + 1),
+		SessionID:  sessionID,
+		PauseStart: pauseStart,
+		IsAfk:      isAfk,
+		Reason:     reason,
+		Note:       note,
+	})
+	return int64(len(m.Pauses)), nil
 }
 
 func (m *mockDB) ResumeSession(sessionID int64, pauseEnd time.Time) error {
 	m.Paused = false
 	m.ResumeSessionCalled = true
 	m.ActiveSession.IsPaused = false
+	m.ActiveSession.IsAfk = false
+	for i := len(m.Pauses) - 1; i >= 0; i-- {
+		if m.Pauses[i].SessionID == sessionID && m.Pauses[i].PauseEnd == nil {
+			m.Pauses[i].PauseEnd = &pauseEnd
+			break
+		}
+	}
+	return nil
+}
+
+func (m *mockDB) ListPauses(sessionID int64) ([]db.Pause, error) {
+	var pauses []db.Pause
+	for _, p := range m.Pauses {
+		if p.SessionID == sessionID {
+			pauses = append(pauses, p)
+		}
+	}
+	return pauses, nil
+}
+
+func (m *mockDB) ListPendingIdle(since time.Time) ([]db.Pause, error) {
+	var pauses []db.Pause
+	for _, p := range m.Pauses {
+		if p.AwaitsDecision() && p.PauseEnd.After(since) {
+			pauses = append(pauses, p)
+		}
+	}
+	return pauses, nil
+}
+
+func (m *mockDB) DecideIdle(pauseID int64, at time.Time, decision db.IdleDecision, activity string) error {
+	for i := range m.Pauses {
+		if m.Pauses[i].ID == pauseID {
+			m.Pauses[i].Decision = decision
+			m.Pauses[i].Activity = activity
+			return nil
+		}
+	}
+	return db.ErrPauseNotFound
+}
+
+func (m *mockDB) RecordWorklog(worklog db.Worklog) error {
+	m.Worklogs = append(m.Worklogs, worklog)
 	return nil
 }
 
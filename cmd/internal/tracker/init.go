@@ -2,15 +2,14 @@ package tracker
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 
+	"github.com/impactj90/lofi-tracker/cmd/internal/config"
 	"github.com/impactj90/lofi-tracker/cmd/internal/db"
 	"github.com/impactj90/lofi-tracker/cmd/internal/git"
 )
 
-func Init() (Tracker, string, error) {
-	dbPath, err := getDBPath()
+func Init(cfg *config.Config) (Tracker, string, error) {
+	dbPath, err := cfg.ResolvedDBPath()
 	if err != nil {
 		fmt.Printf("Failed to get database path: %v\n", err)
 		return nil, "", err
@@ -27,13 +26,28 @@ func Init() (Tracker, string, error) {
 		return nil, "", err
 	}
 
-	return NewTracker(branchName, dbConn), branchName, nil
+	repo, err := git.GetCurrentRepository()
+	if err != nil {
+		fmt.Printf("Failed to get current repository: %v\n", err)
+		return nil, "", err
+	}
+
+	tr, err := ForRepository(cfg, repo, dbConn)
+	if err != nil {
+		fmt.Printf("Failed to configure ticket extraction: %v\n", err)
+		return nil, "", err
+	}
+
+	return tr, branchName, nil
 }
 
-func getDBPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+// ForRepository returns a tracker for sessions in repo, configured by cfg,
+// on an already open database.
+func ForRepository(cfg *config.Config, repo git.Repository, database db.DB) (Tracker, error) {
+	tickets, err := cfg.TicketExtractor()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return filepath.Join(homeDir, ".lofi-tracker", "lofi-tracker.db"), nil
+
+	return NewTracker(repo, database, WithTicketExtractor(tickets), WithProject(cfg.Project)), nil
 }
@@ -0,0 +1,172 @@
+package tracker
+
+import (
+	"errors"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+)
+
+// ErrAlreadyPaused is returned by Pause when the session is paused already,
+// for whatever reason.
+var ErrAlreadyPaused = errors.New("session is already paused")
+
+// ErrNotPaused is returned by Resume and ResumeFromAfk when the session is
+// running.
+var ErrNotPaused = errors.New("session is not paused")
+
+// ErrPausedManually is returned by ResumeFromAfk when the session was paused
+// by hand; only Resume ends such a pause.
+var ErrPausedManually = errors.New("session was paused manually")
+
+// State is where the tracked session stands.
+type State int
+
+const (
+	// Idle means there is no active session
+	Idle State = iota
+	Running
+	PausedManual
+	PausedAfk
+	// Completed is where a session ends up; like Idle it only allows Start
+	Completed
+)
+
+func (s State) String() string {
+	switch s {
+	case Idle:
+		return "idle"
+	case Running:
+		return "running"
+	case PausedManual:
+		return "paused"
+	case PausedAfk:
+		return "afk"
+	case Completed:
+		return "completed"
+	}
+	return "unknown"
+}
+
+// Action is a request to move the session to another state.
+type Action int
+
+const (
+	ActionStart Action = iota
+	ActionPauseManual
+	ActionPauseAfk
+	// ActionResume is the user resuming, which ends any pause
+	ActionResume
+	// ActionAfkReturn is input noticed after an AFK pause
+	ActionAfkReturn
+	ActionComplete
+	ActionSwitch
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionStart:
+		return "start"
+	case ActionPauseManual:
+		return "pause"
+	case ActionPauseAfk:
+		return "afk pause"
+	case ActionResume:
+		return "resume"
+	case ActionAfkReturn:
+		return "afk return"
+	case ActionComplete:
+		return "complete"
+	case ActionSwitch:
+		return "switch"
+	}
+	return "unknown"
+}
+
+// transition is the outcome of an action in a state: the next state, or the
+// error that refuses it.
+type transition struct {
+	next State
+	err  error
+}
+
+func to(next State) transition    { return transition{next: next} }
+func refuse(err error) transition { return transition{err: err} }
+
+// noSession refuses everything but Start when no session is active.
+var noSession = map[Action]transition{
+	ActionStart:       to(Running),
+	ActionPauseManual: refuse(db.ErrNoActiveSession),
+	ActionPauseAfk:    refuse(db.ErrNoActiveSession),
+	ActionResume:      refuse(db.ErrNoActiveSession),
+	ActionAfkReturn:   refuse(db.ErrNoActiveSession),
+	ActionComplete:    refuse(db.ErrNoActiveSession),
+	ActionSwitch:      refuse(db.ErrNoActiveSession),
+}
+
+var transitions = map[State]map[Action]transition{
+	Idle:      noSession,
+	Completed: noSession,
+	Running: {
+		ActionStart:       refuse(db.ErrActiveSessionAlreadyActive),
+		ActionPauseManual: to(PausedManual),
+		ActionPauseAfk:    to(PausedAfk),
+		ActionResume:      refuse(ErrNotPaused),
+		ActionAfkReturn:   refuse(ErrNotPaused),
+		ActionComplete:    to(Completed),
+		ActionSwitch:      to(Running),
+	},
+	PausedManual: {
+		ActionStart:       refuse(db.ErrActiveSessionAlreadyActive),
+		ActionPauseManual: refuse(ErrAlreadyPaused),
+		ActionPauseAfk:    refuse(ErrAlreadyPaused),
+		ActionResume:      to(Running),
+		ActionAfkReturn:   refuse(ErrPausedManually),
+		ActionComplete:    to(Completed),
+		ActionSwitch:      to(Running),
+	},
+	PausedAfk: {
+		ActionStart:       refuse(db.ErrActiveSessionAlreadyActive),
+		ActionPauseManual: refuse(ErrAlreadyPaused),
+		ActionPauseAfk:    refuse(ErrAlreadyPaused),
+		ActionResume:      to(Running),
+		ActionAfkReturn:   to(Running),
+		ActionComplete:    to(Completed),
+		ActionSwitch:      to(Running),
+	},
+}
+
+// Transition returns the state action leads to from state, or the error that
+// refuses it.
+func Transition(state State, action Action) (State, error) {
+	t, ok := transitions[state][action]
+	if !ok {
+		return state, errors.New("unknown state or action")
+	}
+	if t.err != nil {
+		return state, t.err
+	}
+	return t.next, nil
+}
+
+// stateOf returns the state of the active session, or Idle without one.
+func stateOf(session *db.Session) State {
+	switch {
+	case session == nil:
+		return Idle
+	case session.Endtime != nil:
+		return Completed
+	case session.IsPaused && session.IsAfk:
+		return PausedAfk
+	case session.IsPaused:
+		return PausedManual
+	}
+	return Running
+}
+
+// pauseAction is the action of pausing for reason.
+func pauseAction(reason db.PauseReason) Action {
+	if reason.IsAfk() {
+		return ActionPauseAfk
+	}
+	return ActionPauseManual
+}
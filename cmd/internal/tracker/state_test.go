@@ -0,0 +1,183 @@
+package tracker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+)
+
+func TestTransition_ShouldFollowTheTable(t *testing.T) {
+	tests := []struct {
+		from   State
+		action Action
+		want   State
+		err    error
+	}{
+		{Idle, ActionStart, Running, nil},
+		{Idle, ActionPauseManual, Idle, db.ErrNoActiveSession},
+		{Idle, ActionPauseAfk, Idle, db.ErrNoActiveSession},
+		{Idle, ActionResume, Idle, db.ErrNoActiveSession},
+		{Idle, ActionAfkReturn, Idle, db.ErrNoActiveSession},
+		{Idle, ActionComplete, Idle, db.ErrNoActiveSession},
+		{Idle, ActionSwitch, Idle, db.ErrNoActiveSession},
+
+		{Running, ActionStart, Running, db.ErrActiveSessionAlreadyActive},
+		{Running, ActionPauseManual, PausedManual, nil},
+		{Running, ActionPauseAfk, PausedAfk, nil},
+		{Running, ActionResume, Running, ErrNotPaused},
+		{Running, ActionAfkReturn, Running, ErrNotPaused},
+		{Running, ActionComplete, Completed, nil},
+		{Running, ActionSwitch, Running, nil},
+
+		{PausedManual, ActionStart, PausedManual, db.ErrActiveSessionAlreadyActive},
+		{PausedManual, ActionPauseManual, PausedManual, ErrAlreadyPaused},
+		{PausedManual, ActionPauseAfk, PausedManual, ErrAlreadyPaused},
+		{PausedManual, ActionResume, Running, nil},
+		{PausedManual, ActionAfkReturn, PausedManual, ErrPausedManually},
+		{PausedManual, ActionComplete, Completed, nil},
+		{PausedManual, ActionSwitch, Running, nil},
+
+		{PausedAfk, ActionStart, PausedAfk, db.ErrActiveSessionAlreadyActive},
+		{PausedAfk, ActionPauseManual, PausedAfk, ErrAlreadyPaused},
+		{PausedAfk, ActionPauseAfk, PausedAfk, ErrAlreadyPaused},
+		{PausedAfk, ActionResume, Running, nil},
+		{PausedAfk, ActionAfkReturn, Running, nil},
+		{PausedAfk, ActionComplete, Completed, nil},
+		{PausedAfk, ActionSwitch, Running, nil},
+
+		{Completed, ActionStart, Running, nil},
+		{Completed, ActionPauseManual, Completed, db.ErrNoActiveSession},
+		{Completed, ActionPauseAfk, Completed, db.ErrNoActiveSession},
+		{Completed, ActionResume, Completed, db.ErrNoActiveSession},
+		{Completed, ActionAfkReturn, Completed, db.ErrNoActiveSession},
+		{Completed, ActionComplete, Completed, db.ErrNoActiveSession},
+		{Completed, ActionSwitch, Completed, db.ErrNoActiveSession},
+	}
+
+	covered := map[State]map[Action]bool{}
+	for _, tt := range tests {
+		t.Run(tt.from.String()+"/"+tt.action.String(), func(t *testing.T) {
+			got, err := Transition(tt.from, tt.action)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected state %s, got %s", tt.want, got)
+			}
+		})
+		if covered[tt.from] == nil {
+			covered[tt.from] = map[Action]bool{}
+		}
+		covered[tt.from][tt.action] = true
+	}
+
+	for state, actions := range transitions {
+		for action := range actions {
+			if !covered[state][action] {
+				t.Errorf("expected a test for %s/%s, got none", state, action)
+			}
+		}
+	}
+}
+
+func TestStateOf_ShouldFollowTheSessionFlags(t *testing.T) {
+	end := testNow
+	tests := []struct {
+		name    string
+		session *db.Session
+		want    State
+	}{
+		{"no session", nil, Idle},
+		{"running", &db.Session{}, Running},
+		{"paused", &db.Session{IsPaused: true}, PausedManual},
+		{"afk", &db.Session{IsPaused: true, IsAfk: true}, PausedAfk},
+		{"completed", &db.Session{Endtime: &end}, Completed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stateOf(tt.session); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPause_WhenAlreadyPaused_ShouldRefuse(t *testing.T) {
+	mock := &mockDB{
+		ActiveSession: &db.Session{ID: 1, Branch: "feature/test", StartTime: testNow, IsPaused: true},
+		Paused:        true,
+	}
+
+	err := NewTracker(testRepo, mock).Pause(db.PauseReasonAfk, "")
+	if !errors.Is(err, ErrAlreadyPaused) {
+		t.Fatalf("expected ErrAlreadyPaused, got %v", err)
+	}
+	if mock.PauseSessionCalled {
+		t.Errorf("expected PauseSession not to be called, but it was")
+	}
+}
+
+func TestResume_WhenRunning_ShouldRefuse(t *testing.T) {
+	mock := &mockDB{
+		ActiveSession: &db.Session{ID: 1, Branch: "feature/test", StartTime: testNow},
+	}
+
+	err := NewTracker(testRepo, mock).Resume()
+	if !errors.Is(err, ErrNotPaused) {
+		t.Fatalf("expected ErrNotPaused, got %v", err)
+	}
+	if mock.ResumeSessionCalled {
+		t.Errorf("expected ResumeSession not to be called, but it was")
+	}
+}
+
+func TestResumeFromAfk_WhenPausedManually_ShouldKeepThePause(t *testing.T) {
+	mock := &mockDB{
+		ActiveSession: &db.Session{ID: 1, Branch: "feature/test", StartTime: testNow},
+	}
+	tracker := NewTracker(testRepo, mock)
+
+	if err := tracker.Pause(db.PauseReasonManual, "meeting"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err := tracker.ResumeFromAfk()
+	if !errors.Is(err, ErrPausedManually) {
+		t.Fatalf("expected ErrPausedManually, got %v", err)
+	}
+	if !mock.ActiveSession.IsPaused {
+		t.Errorf("expected the session to stay paused, but it was resumed")
+	}
+
+	if err := tracker.Resume(); err != nil {
+		t.Fatalf("expected Resume to end the manual pause, got %v", err)
+	}
+}
+
+func TestResumeFromAfk_WhenAfk_ShouldResume(t *testing.T) {
+	mock := &mockDB{
+		ActiveSession: &db.Session{ID: 1, Branch: "feature/test", StartTime: testNow},
+	}
+	tracker := NewTracker(testRepo, mock)
+
+	if err := tracker.Pause(db.PauseReasonAfk, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	status, err := tracker.Status()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.State != PausedAfk {
+		t.Errorf("expected state %s, got %s", PausedAfk, status.State)
+	}
+
+	if err := tracker.ResumeFromAfk(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if mock.ActiveSession.IsPaused {
+		t.Errorf("expected the session to run again, but it is paused")
+	}
+}
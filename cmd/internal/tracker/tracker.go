@@ -4,76 +4,171 @@ import (
 	"errors"
 	"time"
 
+	"github.com/impactj90/lofi-tracker/cmd/internal/config"
 	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/ticket"
+	"github.com/impactj90/lofi-tracker/cmd/internal/timer"
 )
 
+// ErrTrackingDisabled is returned by Start in a repository whose config sets
+// project.tracking to false.
+var ErrTrackingDisabled = errors.New("tracking is disabled for this repository")
+
+// ErrAlreadyOnBranch is returned by Switch when the active session already
+// tracks the branch.
+var ErrAlreadyOnBranch = errors.New("already tracking this branch")
+
+// pendingIdleWindow is how far back PendingIdle looks. Idle time nobody
+// decided on within it stays a pause.
+const pendingIdleWindow = 7 * 24 * time.Hour
+
 type Tracker interface {
 	Start(branch string) error
-	Pause(isAfk bool) error
+	Pause(reason db.PauseReason, note string) error
+	// PauseAt pauses as of at, such as the last input before going AFK. at is
+	// moved into the session, after any earlier pause and not past now.
+	PauseAt(at time.Time, reason db.PauseReason, note string) error
 	Resume() error
+	// ResumeFromAfk ends an AFK pause once input is noticed again. Unlike
+	// Resume it leaves a manual pause alone and returns ErrPausedManually.
+	ResumeFromAfk() error
+	// DecideIdle keeps the time of an ended AFK pause as work, labelled with
+	// activity if given, or discards it so it stays a pause.
+	DecideIdle(pauseID int64, decision db.IdleDecision, activity string) error
+	// PendingIdle returns the ended AFK pauses of recent sessions, running or
+	// completed, that are still to be kept or discarded, oldest first.
+	PendingIdle() ([]db.Pause, error)
 	Status() (SessionStatus, error)
 	Complete() (SessionStatus, error)
+	// Switch completes the active session and starts one on branch at the
+	// same instant, returning the status of the completed session.
+	Switch(branch string) (SessionStatus, error)
+	// Undo reverts the most recent Start, Pause, Resume, Complete or Switch
+	// that was not undone yet, and returns what it reverted.
+	Undo() (db.Operation, error)
 	Close() error
 }
 
 type SessionStatus struct {
-	Branch        string
-	StartedAt     time.Time
-	TotalDuration time.Duration
-	IsPaused      bool
-	IsAfk         bool
+	Branch    string
+	Ticket    string
+	RepoPath  string
+	RepoID    string
+	StartedAt time.Time
+	// TotalDuration is the worked time: wall time since StartedAt minus all pauses
+	TotalDuration       time.Duration
+	PausedDuration      time.Duration
+	AfkDuration         time.Duration
+	ManualPauseDuration time.Duration
+	// PausedByReason breaks PausedDuration down by the reason of each pause
+	PausedByReason map[db.PauseReason]time.Duration
+	IsPaused       bool
+	IsAfk          bool
+	State          State
+	// PauseReason is the reason of the open pause while IsPaused is set
+	PauseReason db.PauseReason
+	// KeptIdleDuration is AFK time kept as work, included in TotalDuration
+	KeptIdleDuration time.Duration
+	// KeptByActivity breaks KeptIdleDuration down by activity, "" being
+	// unlabelled
+	KeptByActivity map[string]time.Duration
 }
 
 type tracker struct {
-	repoName string
-	db       db.DB
+	repo       git.Repository
+	db         db.DB
+	tickets    *ticket.Extractor
+	project    config.ProjectConfig
+	clock      timer.Clock
+	headCommit func(dir string) (string, error)
+}
+
+// Option customizes a Tracker created by NewTracker.
+type Option func(*tracker)
+
+// WithTicketExtractor sets how ticket keys are derived from branch names.
+func WithTicketExtractor(e *ticket.Extractor) Option {
+	return func(t *tracker) {
+		t.tickets = e
+	}
 }
 
-func NewTracker(repoName string, db db.DB) Tracker {
-	return &tracker{
-		repoName: repoName,
-		db:       db,
+// WithProject sets the client, project and default tags recorded on new
+// sessions, and whether sessions may be started at all.
+func WithProject(p config.ProjectConfig) Option {
+	return func(t *tracker) {
+		t.project = p
+	}
+}
+
+// WithClock sets the clock that session and pause times are taken from.
+func WithClock(c timer.Clock) Option {
+	return func(t *tracker) {
+		t.clock = c
+	}
+}
+
+// NewTracker returns a Tracker that records new sessions against repo.
+func NewTracker(repo git.Repository, db db.DB, opts ...Option) Tracker {
+	t := &tracker{
+		repo:       repo,
+		db:         db,
+		tickets:    ticket.Default(),
+		project:    config.ProjectConfig{Tracking: true},
+		clock:      timer.Real(),
+		headCommit: git.GetHeadCommit,
+	}
+	for _, opt := range opts {
+		opt(t)
 	}
+	return t
 }
 
 // Complete implements Tracker.
 func (t *tracker) Complete() (SessionStatus, error) {
-	activeSession, err := t.db.GetActiveSession()
-	if err != nil && !errors.Is(err, db.ErrNoActiveSession) {
+	activeSession, err := t.activeSessionFor(ActionComplete)
+	if err != nil {
 		return SessionStatus{}, err
 	}
 
-	if activeSession == nil {
-		return SessionStatus{}, db.ErrNoActiveSession
+	pauses, err := t.db.ListPauses(activeSession.ID)
+	if err != nil {
+		return SessionStatus{}, err
 	}
 
-	endTime := time.Now().UTC()
-	t.db.CompleteSession(activeSession.ID, endTime)
+	endTime := t.clock.Now().UTC()
+	err = t.db.CompleteSession(activeSession.ID, endTime, t.commitAt(activeSession.RepoPath))
 	if err != nil {
 		return SessionStatus{}, err
 	}
 
-	return SessionStatus{
-		Branch:        activeSession.Branch,
-		StartedAt:     activeSession.StartTime,
-		TotalDuration: endTime.Sub(activeSession.StartTime),
-		IsPaused:      false,
-		IsAfk:         false,
-	}, nil
+	status := newSessionStatus(activeSession, pauses, endTime)
+	status.IsPaused = false
+	status.IsAfk = false
+	status.State = Completed
+
+	return status, nil
 }
 
 // Pause implements Tracker.
-func (t *tracker) Pause(isAfk bool) error {
-	activeSession, err := t.db.GetActiveSession()
-	if err != nil && !errors.Is(err, db.ErrNoActiveSession) {
+func (t *tracker) Pause(reason db.PauseReason, note string) error {
+	return t.PauseAt(t.clock.Now(), reason, note)
+}
+
+// PauseAt implements Tracker.
+func (t *tracker) PauseAt(at time.Time, reason db.PauseReason, note string) error {
+	activeSession, err := t.activeSessionFor(pauseAction(reason))
+	if err != nil {
 		return err
 	}
 
-	if activeSession == nil {
-		return db.ErrNoActiveSession
+	pauses, err := t.db.ListPauses(activeSession.ID)
+	if err != nil {
+		return err
 	}
 
-	_, err = t.db.PauseSession(activeSession.ID, time.Now().UTC(), isAfk)
+	_, err = t.db.PauseSession(activeSession.ID, t.pauseStart(activeSession, pauses, at), reason, note)
 	if err != nil {
 		return err
 	}
@@ -81,18 +176,43 @@ func (t *tracker) Pause(isAfk bool) error {
 	return nil
 }
 
+// pauseStart moves at between the end of the last pause, or the start of the
+// session, and now, so a backdated pause overlaps nothing already counted.
+func (t *tracker) pauseStart(session *db.Session, pauses []db.Pause, at time.Time) time.Time {
+	at = at.UTC()
+	if now := t.clock.Now().UTC(); at.After(now) {
+		at = now
+	}
+
+	earliest := session.StartTime
+	for _, p := range pauses {
+		if p.PauseEnd != nil && p.PauseEnd.After(earliest) {
+			earliest = *p.PauseEnd
+		}
+	}
+	if at.Before(earliest) {
+		return earliest.UTC()
+	}
+	return at
+}
+
 // Resume implements Tracker.
 func (t *tracker) Resume() error {
-	activeSession, err := t.db.GetActiveSession()
-	if err != nil && !errors.Is(err, db.ErrNoActiveSession) {
-		return err
-	}
+	return t.resume(ActionResume)
+}
 
-	if activeSession == nil {
-		return db.ErrNoActiveSession
+// ResumeFromAfk implements Tracker.
+func (t *tracker) ResumeFromAfk() error {
+	return t.resume(ActionAfkReturn)
+}
+
+func (t *tracker) resume(action Action) error {
+	activeSession, err := t.activeSessionFor(action)
+	if err != nil {
+		return err
 	}
 
-	err = t.db.ResumeSession(activeSession.ID, time.Now().UTC())
+	err = t.db.ResumeSession(activeSession.ID, t.clock.Now().UTC())
 	if err != nil {
 		return err
 	}
@@ -102,16 +222,15 @@ func (t *tracker) Resume() error {
 
 // Start implements Tracker.
 func (t *tracker) Start(branch string) error {
-	activeSession, err := t.db.GetActiveSession()
-	if err != nil && !errors.Is(err, db.ErrNoActiveSession) {
-		return err
+	if !t.project.Tracking {
+		return ErrTrackingDisabled
 	}
 
-	if activeSession != nil {
-		return db.ErrActiveSessionAlreadyActive
+	if _, err := t.activeSessionFor(ActionStart); err != nil {
+		return err
 	}
 
-	_, err = t.db.CreateSession(branch, time.Now().UTC())
+	_, err := t.db.CreateSession(t.newSession(branch, t.clock.Now().UTC()))
 	if err != nil {
 		return err
 	}
@@ -119,6 +238,86 @@ func (t *tracker) Start(branch string) error {
 	return nil
 }
 
+// Switch implements Tracker.
+func (t *tracker) Switch(branch string) (SessionStatus, error) {
+	if !t.project.Tracking {
+		return SessionStatus{}, ErrTrackingDisabled
+	}
+
+	activeSession, err := t.activeSessionFor(ActionSwitch)
+	if err != nil {
+		return SessionStatus{}, err
+	}
+
+	if activeSession.Branch == branch && activeSession.RepoID == t.repo.ID {
+		return SessionStatus{}, ErrAlreadyOnBranch
+	}
+
+	pauses, err := t.db.ListPauses(activeSession.ID)
+	if err != nil {
+		return SessionStatus{}, err
+	}
+
+	now := t.clock.Now().UTC()
+	_, err = t.db.SwitchSession(activeSession.ID, now, t.commitAt(activeSession.RepoPath), t.newSession(branch, now))
+	if err != nil {
+		return SessionStatus{}, err
+	}
+
+	status := newSessionStatus(activeSession, pauses, now)
+	status.IsPaused = false
+	status.IsAfk = false
+	status.State = Completed
+
+	return status, nil
+}
+
+// Undo implements Tracker.
+func (t *tracker) Undo() (db.Operation, error) {
+	return t.db.UndoLastOperation()
+}
+
+// activeSessionFor returns the active session, or nil when there is none,
+// after checking that action is allowed in its state.
+func (t *tracker) activeSessionFor(action Action) (*db.Session, error) {
+	activeSession, err := t.db.GetActiveSession()
+	if err != nil && !errors.Is(err, db.ErrNoActiveSession) {
+		return nil, err
+	}
+
+	if _, err := Transition(stateOf(activeSession), action); err != nil {
+		return nil, err
+	}
+
+	return activeSession, nil
+}
+
+// newSession returns a session on branch in the tracker's repository.
+func (t *tracker) newSession(branch string, start time.Time) db.Session {
+	ticketKey, _ := t.tickets.Extract(branch)
+	return db.Session{
+		Branch:      branch,
+		Ticket:      ticketKey,
+		Client:      t.project.Client,
+		Project:     t.project.Name,
+		RepoPath:    t.repo.Root,
+		RepoID:      t.repo.ID,
+		StartCommit: t.commitAt(t.repo.Root),
+		StartTime:   start,
+		Tags:        t.project.Tags,
+	}
+}
+
+// DecideIdle implements Tracker.
+func (t *tracker) DecideIdle(pauseID int64, decision db.IdleDecision, activity string) error {
+	return t.db.DecideIdle(pauseID, t.clock.Now().UTC(), decision, activity)
+}
+
+// PendingIdle implements Tracker.
+func (t *tracker) PendingIdle() ([]db.Pause, error) {
+	return t.db.ListPendingIdle(t.clock.Now().Add(-pendingIdleWindow))
+}
+
 // Status implements Tracker.
 func (t *tracker) Status() (SessionStatus, error) {
 	activeSession, err := t.db.GetActiveSession()
@@ -130,13 +329,96 @@ func (t *tracker) Status() (SessionStatus, error) {
 		return SessionStatus{}, db.ErrNoActiveSession
 	}
 
-	return SessionStatus{
-		Branch:        activeSession.Branch,
-		StartedAt:     activeSession.StartTime,
-		TotalDuration: time.Now().UTC().Sub(activeSession.StartTime),
-		IsPaused:      activeSession.IsPaused,
-		IsAfk:         activeSession.IsAfk,
-	}, nil
+	pauses, err := t.db.ListPauses(activeSession.ID)
+	if err != nil {
+		return SessionStatus{}, err
+	}
+
+	return newSessionStatus(activeSession, pauses, t.clock.Now().UTC()), nil
+}
+
+// newSessionStatus builds the status of a session as of now. Pauses that are
+// still open count up to now, and every pause is clipped to the session bounds
+// so a stray row can never push the worked time below zero.
+func newSessionStatus(session *db.Session, pauses []db.Pause, now time.Time) SessionStatus {
+	status := SessionStatus{
+		Branch:         session.Branch,
+		Ticket:         session.Ticket,
+		RepoPath:       session.RepoPath,
+		RepoID:         session.RepoID,
+		StartedAt:      session.StartTime,
+		PausedByReason: map[db.PauseReason]time.Duration{},
+		KeptByActivity: map[string]time.Duration{},
+		IsPaused:       session.IsPaused,
+		IsAfk:          session.IsAfk,
+		State:          stateOf(session),
+	}
+
+	end := now
+	if session.Endtime != nil {
+		end = *session.Endtime
+	}
+
+	for _, p := range pauses {
+		reason := p.Reason
+		if reason == "" {
+			reason = db.PauseReasonManual
+			if p.IsAfk {
+				reason = db.PauseReasonAfk
+			}
+		}
+		if p.PauseEnd == nil && session.IsPaused {
+			status.PauseReason = reason
+		}
+
+		pauseStart := p.PauseStart
+		pauseEnd := end
+		if p.PauseEnd != nil && p.PauseEnd.Before(end) {
+			pauseEnd = *p.PauseEnd
+		}
+		if pauseStart.Before(session.StartTime) {
+			pauseStart = session.StartTime
+		}
+		if !pauseEnd.After(pauseStart) {
+			continue
+		}
+
+		d := pauseEnd.Sub(pauseStart)
+		if p.IsKept() {
+			status.KeptIdleDuration += d
+			status.KeptByActivity[p.Activity] += d
+			continue
+		}
+		status.PausedByReason[reason] += d
+		if p.IsAfk {
+			status.AfkDuration += d
+		} else {
+			status.ManualPauseDuration += d
+		}
+	}
+
+	status.PausedDuration = status.AfkDuration + status.ManualPauseDuration
+	status.TotalDuration = end.Sub(session.StartTime) - status.PausedDuration
+	if status.TotalDuration < 0 {
+		status.TotalDuration = 0
+	}
+
+	return status
+}
+
+// commitAt returns the HEAD commit of the repository at dir. Sessions are still
+// tracked in repositories without commits, so a failed lookup records no commit.
+func (t *tracker) commitAt(dir string) string {
+	if dir == "" {
+		return ""
+	}
+
+	commit, err := t.headCommit(dir)
+	if err != nil {
+		return ""
+	}
+
+	return commit
 }
 
 func (t *tracker) Close() error {
@@ -1,16 +1,27 @@
 package tracker
 
 import (
+	"errors"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/impactj90/lofi-tracker/cmd/internal/config"
 	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/timer"
 )
 
+var testRepo = git.Repository{Root: "/src/lofi-tracker", ID: "4b825dc642cb6eb9a060e54bf8d69288fbee4904"}
+
+// testNow is where the fake clocks of the tests start
+var testNow = time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC)
+
 func TestStart_WhenNoActiveSession_ShouldCreateNewSession(t *testing.T) {
 	mock := &mockDB{}
 
-	tracker := NewTracker("lofi-tracker", mock)
+	tracker := NewTracker(testRepo, mock)
 
 	err := tracker.Start("feature/awesome")
 	if err != nil {
@@ -31,14 +42,16 @@ func TestPause_WhenActiveSessionExists_ShouldPauseSession(t *testing.T) {
 		ActiveSession: &db.Session{
 			ID:        1,
 			Branch:    "feature/test",
-			StartTime: time.Now(),
+			StartTime: testNow,
 			IsPaused:  false,
 		},
 	}
 
-	tracker := NewTracker("lofi-tracker", mock)
+	clock := timer.NewFake(testNow)
+	tracker := NewTracker(testRepo, mock, WithClock(clock))
 
-	err := tracker.Pause(false)
+	clock.Advance(20 * time.Minute)
+	err := tracker.Pause(db.PauseReasonManual, "")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -50,6 +63,194 @@ func TestPause_WhenActiveSessionExists_ShouldPauseSession(t *testing.T) {
 	if !mock.ActiveSession.IsPaused {
 		t.Errorf("expected session to be paused, but IsPaused is false")
 	}
+
+	if want := testNow.Add(20 * time.Minute); len(mock.Pauses) != 1 || !mock.Pauses[0].PauseStart.Equal(want) {
+		t.Errorf("expected one pause starting at %v, got %+v", want, mock.Pauses)
+	}
+}
+
+func TestPauseAt_ShouldCountTheTimeSinceAtAsPaused(t *testing.T) {
+	mock := &mockDB{}
+	clock := timer.NewFake(testNow)
+	tracker := NewTracker(testRepo, mock, WithClock(clock))
+
+	if err := tracker.Start("main"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	clock.Advance(time.Hour)
+
+	// Noticed 15 minutes after the last input
+	if err := tracker.PauseAt(clock.Now().Add(-15*time.Minute), db.PauseReasonAfk, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := testNow.Add(45 * time.Minute); !mock.Pauses[0].PauseStart.Equal(want) {
+		t.Errorf("expected the pause to start at %v, got %v", want, mock.Pauses[0].PauseStart)
+	}
+
+	status, err := tracker.Status()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.AfkDuration != 15*time.Minute || status.TotalDuration != 45*time.Minute {
+		t.Errorf("expected 45m of work and 15m AFK, got %v and %v", status.TotalDuration, status.AfkDuration)
+	}
+}
+
+func TestPauseAt_WhenAtIsOutsideTheSession_ShouldMoveItIn(t *testing.T) {
+	mock := &mockDB{}
+	clock := timer.NewFake(testNow)
+	tracker := NewTracker(testRepo, mock, WithClock(clock))
+
+	if err := tracker.Start("main"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	clock.Advance(10 * time.Minute)
+	if err := tracker.PauseAt(testNow.Add(-time.Hour), db.PauseReasonAfk, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !mock.Pauses[0].PauseStart.Equal(testNow) {
+		t.Errorf("expected the pause to start with the session at %v, got %v", testNow, mock.Pauses[0].PauseStart)
+	}
+
+	clock.Advance(5 * time.Minute)
+	if err := tracker.Resume(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	clock.Advance(30 * time.Minute)
+	if err := tracker.PauseAt(testNow, db.PauseReasonAfk, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := testNow.Add(15 * time.Minute); !mock.Pauses[1].PauseStart.Equal(want) {
+		t.Errorf("expected the pause to start when the last one ended at %v, got %v", want, mock.Pauses[1].PauseStart)
+	}
+
+	if err := tracker.Resume(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := tracker.PauseAt(clock.Now().Add(time.Hour), db.PauseReasonManual, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !mock.Pauses[2].PauseStart.Equal(clock.Now()) {
+		t.Errorf("expected a pause ahead to start now at %v, got %v", clock.Now(), mock.Pauses[2].PauseStart)
+	}
+}
+
+func TestDecideIdle_WhenKept_ShouldCountTheAfkPauseAsWork(t *testing.T) {
+	mock := &mockDB{}
+	clock := timer.NewFake(testNow)
+	tracker := NewTracker(testRepo, mock, WithClock(clock))
+
+	if err := tracker.Start("main"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	clock.Advance(time.Hour)
+	if err := tracker.PauseAt(clock.Now().Add(-15*time.Minute), db.PauseReasonAfk, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	clock.Advance(27 * time.Minute)
+	if err := tracker.ResumeFromAfk(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	status, err := tracker.Status()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	pending, err := tracker.PendingIdle()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(pending) != 1 || status.TotalDuration != 45*time.Minute {
+		t.Fatalf("expected 42m idle to await a decision after 45m of work, got %+v and %+v", pending, status)
+	}
+
+	if err := tracker.DecideIdle(pending[0].ID, db.IdleKept, "call"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	status, err = tracker.Status()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pending, _ := tracker.PendingIdle(); len(pending) != 0 {
+		t.Errorf("expected nothing to await a decision, got %+v", pending)
+	}
+	if status.TotalDuration != 87*time.Minute || status.AfkDuration != 0 {
+		t.Errorf("expected 1h27m of work without AFK time, got %v and %v", status.TotalDuration, status.AfkDuration)
+	}
+	if status.KeptIdleDuration != 42*time.Minute || status.KeptByActivity["call"] != 42*time.Minute {
+		t.Errorf("expected 42m kept as a call, got %v (%v)", status.KeptIdleDuration, status.KeptByActivity)
+	}
+}
+
+func TestPendingIdle_WhenCompletedWhileAfk_ShouldStillOfferTheIdleTime(t *testing.T) {
+	mock := &mockDB{}
+	clock := timer.NewFake(testNow)
+	tracker := NewTracker(testRepo, mock, WithClock(clock))
+
+	if err := tracker.Start("main"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	clock.Advance(time.Hour)
+	if err := tracker.Pause(db.PauseReasonAfk, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	clock.Advance(20 * time.Minute)
+	if _, err := tracker.Complete(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	pending, err := tracker.PendingIdle()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected the AFK pause of the completed session to await a decision, got %+v", pending)
+	}
+	if err := tracker.DecideIdle(pending[0].ID, db.IdleKept, "whiteboard"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if p := mock.Pauses[0]; !p.IsKept() || p.Activity != "whiteboard" {
+		t.Errorf("expected the idle time to be kept as whiteboard, got %+v", p)
+	}
+	if pending, _ := tracker.PendingIdle(); len(pending) != 0 {
+		t.Errorf("expected nothing to await a decision, got %+v", pending)
+	}
+}
+
+func TestStatus_WhenClockAdvancesPastMidnight_ShouldCountTheWholeSession(t *testing.T) {
+	mock := &mockDB{}
+	evening := time.Date(2026, 10, 17, 22, 30, 0, 0, time.UTC)
+	clock := timer.NewFake(evening)
+	tracker := NewTracker(testRepo, mock, WithClock(clock))
+
+	if err := tracker.Start("main"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	clock.Advance(time.Hour)
+	if err := tracker.Pause(db.PauseReasonAfk, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	clock.Advance(15 * time.Minute)
+	if err := tracker.Resume(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	clock.Advance(45 * time.Minute)
+
+	status, err := tracker.Status()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !status.StartedAt.Equal(evening) {
+		t.Errorf("expected the session to start at %v, got %v", evening, status.StartedAt)
+	}
+	if status.AfkDuration != 15*time.Minute {
+		t.Errorf("expected 15m of AFK time, got %v", status.AfkDuration)
+	}
+	if status.TotalDuration != 105*time.Minute {
+		t.Errorf("expected 1h45m of work, got %v", status.TotalDuration)
+	}
 }
 
 func TestResume_WhenSessionIsPaused_ShouldResumeSession(t *testing.T) {
@@ -57,13 +258,13 @@ func TestResume_WhenSessionIsPaused_ShouldResumeSession(t *testing.T) {
 		ActiveSession: &db.Session{
 			ID:        1,
 			Branch:    "feature/test",
-			StartTime: time.Now(),
+			StartTime: testNow,
 			IsPaused:  true,
 		},
 		Paused: true,
 	}
 
-	tracker := NewTracker("lofi-tracker", mock)
+	tracker := NewTracker(testRepo, mock)
 
 	err := tracker.Resume()
 	if err != nil {
@@ -84,12 +285,12 @@ func TestComplete_WhenActiveSessionExists_ShouldCompleteSession(t *testing.T) {
 		ActiveSession: &db.Session{
 			ID:        1,
 			Branch:    "feature/test",
-			StartTime: time.Now().Add(-2 * time.Hour), // Assume started 2 hours ago
+			StartTime: testNow.Add(-2 * time.Hour),
 			IsPaused:  false,
 		},
 	}
 
-	tracker := NewTracker("lofi-tracker", mock)
+	tracker := NewTracker(testRepo, mock, WithClock(timer.NewFake(testNow)))
 
 	status, err := tracker.Complete()
 	if err != nil {
@@ -100,11 +301,308 @@ func TestComplete_WhenActiveSessionExists_ShouldCompleteSession(t *testing.T) {
 		t.Errorf("expected branch 'feature/test', got %s", status.Branch)
 	}
 
-	if status.TotalDuration < 2*time.Hour {
-		t.Errorf("expected at least 2 hours of work, got %v", status.TotalDuration)
+	if status.TotalDuration != 2*time.Hour {
+		t.Errorf("expected 2 hours of work, got %v", status.TotalDuration)
+	}
+
+	if end := mock.ActiveSession.Endtime; end == nil || !end.Equal(testNow) {
+		t.Errorf("expected the session to end at %v, got %v", testNow, end)
 	}
 
 	if status.IsPaused {
 		t.Errorf("expected session to be not paused after completion")
 	}
 }
+
+func TestStatus_WhenSessionHasPauses_ShouldSubtractPausedTime(t *testing.T) {
+	now := testNow
+	lunchEnd := now.Add(-2 * time.Hour)
+	mock := &mockDB{
+		ActiveSession: &db.Session{
+			ID:        1,
+			Branch:    "feature/test",
+			StartTime: now.Add(-6 * time.Hour),
+			IsPaused:  true,
+			IsAfk:     false,
+		},
+		Pauses: []db.Pause{
+			{ID: 1, SessionID: 1, PauseStart: now.Add(-4 * time.Hour), PauseEnd: &lunchEnd, IsAfk: true},
+			{ID: 2, SessionID: 1, PauseStart: now.Add(-30 * time.Minute), IsAfk: false},
+		},
+	}
+
+	tracker := NewTracker(testRepo, mock, WithClock(timer.NewFake(now)))
+
+	status, err := tracker.Status()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if status.AfkDuration != 2*time.Hour {
+		t.Errorf("expected 2h of AFK time, got %v", status.AfkDuration)
+	}
+
+	if status.ManualPauseDuration != 30*time.Minute {
+		t.Errorf("expected 30m of manual pause time, got %v", status.ManualPauseDuration)
+	}
+
+	if status.PausedDuration != status.AfkDuration+status.ManualPauseDuration {
+		t.Errorf("expected paused time to be the sum of AFK and manual pauses, got %v", status.PausedDuration)
+	}
+
+	if status.TotalDuration != 3*time.Hour+30*time.Minute {
+		t.Errorf("expected 3h30m of work, got %v", status.TotalDuration)
+	}
+}
+
+func TestComplete_WhenSessionIsPaused_ShouldNotCountOpenPause(t *testing.T) {
+	mock := &mockDB{
+		ActiveSession: &db.Session{
+			ID:        1,
+			Branch:    "feature/test",
+			StartTime: testNow.Add(-3 * time.Hour),
+			IsPaused:  true,
+			IsAfk:     true,
+		},
+		Pauses: []db.Pause{
+			{ID: 1, SessionID: 1, PauseStart: testNow.Add(-1 * time.Hour), IsAfk: true},
+		},
+	}
+
+	tracker := NewTracker(testRepo, mock, WithClock(timer.NewFake(testNow)))
+
+	status, err := tracker.Complete()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if status.TotalDuration != 2*time.Hour {
+		t.Errorf("expected 2h of work, got %v", status.TotalDuration)
+	}
+
+	if status.AfkDuration != time.Hour {
+		t.Errorf("expected 1h of AFK time, got %v", status.AfkDuration)
+	}
+
+	if mock.Pauses[0].PauseEnd == nil {
+		t.Errorf("expected open pause to be closed on completion")
+	}
+}
+
+func TestStatus_WithSQLiteDB_ShouldSubtractPausedTime(t *testing.T) {
+	sqlite, err := db.NewSQLiteDB(filepath.Join(t.TempDir(), "lofi-tracker.db"))
+	if err != nil {
+		t.Fatalf("expected no error opening database, got %v", err)
+	}
+	defer sqlite.Close()
+
+	now := testNow
+	sessionID, err := sqlite.CreateSession(db.Session{Branch: "feature/sqlite", StartTime: now.Add(-4 * time.Hour)})
+	if err != nil {
+		t.Fatalf("expected no error creating session, got %v", err)
+	}
+
+	if _, err := sqlite.PauseSession(sessionID, now.Add(-3*time.Hour), db.PauseReasonAfk, ""); err != nil {
+		t.Fatalf("expected no error pausing session, got %v", err)
+	}
+	if err := sqlite.ResumeSession(sessionID, now.Add(-2*time.Hour)); err != nil {
+		t.Fatalf("expected no error resuming session, got %v", err)
+	}
+	if _, err := sqlite.PauseSession(sessionID, now.Add(-1*time.Hour), db.PauseReasonLunch, "sandwich"); err != nil {
+		t.Fatalf("expected no error pausing session, got %v", err)
+	}
+	if err := sqlite.ResumeSession(sessionID, now.Add(-30*time.Minute)); err != nil {
+		t.Fatalf("expected no error resuming session, got %v", err)
+	}
+
+	tracker := NewTracker(testRepo, sqlite, WithClock(timer.NewFake(now)))
+
+	status, err := tracker.Status()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if status.AfkDuration != time.Hour {
+		t.Errorf("expected 1h of AFK time, got %v", status.AfkDuration)
+	}
+
+	if status.ManualPauseDuration != 30*time.Minute {
+		t.Errorf("expected 30m of manual pause time, got %v", status.ManualPauseDuration)
+	}
+
+	if status.PausedByReason[db.PauseReasonLunch] != 30*time.Minute || status.PausedByReason[db.PauseReasonAfk] != time.Hour {
+		t.Errorf("expected 30m lunch and 1h AFK, got %v", status.PausedByReason)
+	}
+
+	if status.TotalDuration != 2*time.Hour+30*time.Minute {
+		t.Errorf("expected 2h30m of work, got %v", status.TotalDuration)
+	}
+}
+
+func TestStartAndComplete_ShouldRecordRepositoryAndCommits(t *testing.T) {
+	mock := &mockDB{}
+
+	tr := NewTracker(testRepo, mock).(*tracker)
+	commits := []string{"1111111", "2222222"}
+	tr.headCommit = func(dir string) (string, error) {
+		if dir != testRepo.Root {
+			t.Errorf("expected commit lookup in %s, got %s", testRepo.Root, dir)
+		}
+		commit := commits[0]
+		commits = commits[1:]
+		return commit, nil
+	}
+
+	if err := tr.Start("main"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	session := mock.ActiveSession
+	if session.RepoPath != testRepo.Root || session.RepoID != testRepo.ID {
+		t.Errorf("expected session in repository %+v, got path %q id %q", testRepo, session.RepoPath, session.RepoID)
+	}
+	if session.StartCommit != "1111111" {
+		t.Errorf("expected start commit 1111111, got %q", session.StartCommit)
+	}
+
+	status, err := tr.Complete()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.RepoID != testRepo.ID {
+		t.Errorf("expected status for repository %s, got %s", testRepo.ID, status.RepoID)
+	}
+	if session.EndCommit != "2222222" {
+		t.Errorf("expected end commit 2222222, got %q", session.EndCommit)
+	}
+}
+
+func TestStart_ShouldStoreTicketDerivedFromBranch(t *testing.T) {
+	mock := &mockDB{}
+
+	tracker := NewTracker(testRepo, mock)
+
+	if err := tracker.Start("feature/ABC-123-fix-login"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if mock.ActiveSession.Ticket != "ABC-123" {
+		t.Errorf("expected ticket ABC-123, got %q", mock.ActiveSession.Ticket)
+	}
+}
+
+func TestStart_ShouldApplyProjectConfig(t *testing.T) {
+	mock := &mockDB{}
+
+	tracker := NewTracker(testRepo, mock, WithProject(config.ProjectConfig{
+		Client:   "Acme",
+		Name:     "Webshop",
+		Tags:     []string{"billable"},
+		Tracking: true,
+	}))
+
+	if err := tracker.Start("main"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	session := mock.ActiveSession
+	if session.Client != "Acme" || session.Project != "Webshop" {
+		t.Errorf("expected client Acme and project Webshop, got %q and %q", session.Client, session.Project)
+	}
+	if len(session.Tags) != 1 || session.Tags[0] != "billable" {
+		t.Errorf("expected default tag billable, got %v", session.Tags)
+	}
+}
+
+func TestStart_WhenTrackingIsDisabled_ShouldRefuse(t *testing.T) {
+	mock := &mockDB{}
+
+	tracker := NewTracker(testRepo, mock, WithProject(config.ProjectConfig{Tracking: false}))
+
+	if err := tracker.Start("main"); !errors.Is(err, ErrTrackingDisabled) {
+		t.Errorf("expected ErrTrackingDisabled, got %v", err)
+	}
+	if mock.CreateSessionCalled {
+		t.Errorf("expected no session to be created")
+	}
+}
+
+func TestSwitch_ShouldMoveTrackingWithoutAGap(t *testing.T) {
+	mock := &mockDB{
+		ActiveSession: &db.Session{
+			ID:        1,
+			Branch:    "main",
+			RepoID:    testRepo.ID,
+			StartTime: testNow.Add(-time.Hour),
+		},
+	}
+
+	tracker := NewTracker(testRepo, mock, WithClock(timer.NewFake(testNow)))
+
+	status, err := tracker.Switch("feature/ABC-9")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.Branch != "main" {
+		t.Errorf("expected the status of the completed session, got %q", status.Branch)
+	}
+
+	if len(mock.Completed) != 1 {
+		t.Fatalf("expected one completed session, got %d", len(mock.Completed))
+	}
+	ended := mock.Completed[0].Endtime
+	if ended == nil || !ended.Equal(testNow) || !mock.ActiveSession.StartTime.Equal(*ended) {
+		t.Errorf("expected the new session to start when the old one ended, got %v and %v", mock.ActiveSession.StartTime, ended)
+	}
+	if mock.ActiveSession.Ticket != "ABC-9" {
+		t.Errorf("expected ticket ABC-9, got %q", mock.ActiveSession.Ticket)
+	}
+
+	if _, err := tracker.Switch("feature/ABC-9"); !errors.Is(err, ErrAlreadyOnBranch) {
+		t.Errorf("expected ErrAlreadyOnBranch, got %v", err)
+	}
+}
+
+func TestMockListSessions_ShouldApplyTheFilter(t *testing.T) {
+	mock := &mockDB{}
+	clock := timer.NewFake(testNow)
+	tracker := NewTracker(testRepo, mock, WithClock(clock))
+
+	if err := tracker.Start("main"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, branch := range []string{"feature/ABC-1", "feature/sub/ABC-2", "main"} {
+		clock.Advance(time.Hour)
+		if _, err := tracker.Switch(branch); err != nil {
+			t.Fatalf("expected no error switching to %s, got %v", branch, err)
+		}
+	}
+	mock.RecordWorklog(db.Worklog{SessionID: 2, IssueKey: "ABC-1"})
+
+	tests := map[string]struct {
+		filter db.SessionFilter
+		want   []int64
+	}{
+		"all":         {db.SessionFilter{}, []int64{1, 2, 3, 4}},
+		"branch glob": {db.SessionFilter{Branch: "feature/*"}, []int64{2, 3}},
+		"range":       {db.SessionFilter{From: testNow.Add(90 * time.Minute), To: testNow.Add(3 * time.Hour)}, []int64{2, 3}},
+		"active":      {db.SessionFilter{State: db.ActiveSessions}, []int64{4}},
+		"completed":   {db.SessionFilter{State: db.CompletedSessions, Order: db.NewestFirst}, []int64{3, 2, 1}},
+		"repo":        {db.SessionFilter{Repo: testRepo.Root, Limit: 2, Offset: 1}, []int64{2, 3}},
+		"other repo":  {db.SessionFilter{Repo: "elsewhere"}, nil},
+		"unsynced":    {db.SessionFilter{Unsynced: true, Branch: "feature/*"}, []int64{3}},
+	}
+	for name, tt := range tests {
+		sessions, err := mock.ListSessions(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+		var ids []int64
+		for _, s := range sessions {
+			ids = append(ids, s.ID)
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("%s: expected sessions %v, got %v", name, tt.want, ids)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package weekday
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Parse parses an English weekday name such as "monday" or "Sun", in full or
+// by its first three or more letters.
+func Parse(s string) (time.Weekday, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || (len(name) >= 3 && strings.HasPrefix(full, name)) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
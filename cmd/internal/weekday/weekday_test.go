@@ -0,0 +1,19 @@
+package weekday
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for in, want := range map[string]time.Weekday{"monday": time.Monday, "Sun": time.Sunday, " SATURDAY ": time.Saturday} {
+		got, err := Parse(in)
+		if err != nil || got != want {
+			t.Errorf("Parse(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+
+	if _, err := Parse("mo"); err == nil {
+		t.Errorf("expected an error for an ambiguous abbreviation")
+	}
+}
@@ -0,0 +1,210 @@
+// defines the add command
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/config"
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/report"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+// addDayStart is where an entry with only a duration is placed on a day
+// without any sessions.
+const addDayStart = 9 * time.Hour
+
+var (
+	addBranch   string
+	addFrom     string
+	addTo       string
+	addDuration time.Duration
+	addOn       string
+	addTags     []string
+)
+
+func init() {
+	addCmd.Flags().StringVar(&addBranch, "branch", "", "Branch the time is booked on (default the checked out branch)")
+	addCmd.Flags().StringVar(&addFrom, "from", "", "Start time, e.g. 09:00")
+	addCmd.Flags().StringVar(&addTo, "to", "", "End time, e.g. 10:30")
+	addCmd.Flags().DurationVar(&addDuration, "duration", 0, "Length of the entry, e.g. 1h30m, instead of --from or --to")
+	addCmd.Flags().StringVar(&addOn, "on", "today", "Day of the entry: today, yesterday, a weekday or YYYY-MM-DD")
+	addCmd.Flags().StringSliceVar(&addTags, "tag", nil, "Tag the entry, may be repeated")
+	rootCmd.AddCommand(addCmd)
+}
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add time you forgot to track",
+	Long: `Adds a completed session after the fact, marked as manual in reports.
+
+  lofi-tracker add --branch feature/ABC-1 --from 09:00 --to 10:30
+  lofi-tracker add --branch feature/ABC-1 --duration 1h30m --on yesterday
+
+With only --duration, today's entry ends now and an entry on another day
+starts where that day's last session ended, or at 09:00.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("❌ Failed to load config: %v\n", err)
+			return
+		}
+
+		if !cfg.Project.Tracking {
+			fmt.Printf("❌ %v\n", tracker.ErrTrackingDisabled)
+			return
+		}
+
+		repo, _ := git.GetCurrentRepository()
+		branch := addBranch
+		if branch == "" {
+			branch, err = git.GetCurrentBranchName()
+			if err != nil {
+				fmt.Println("❌ No --branch given and no branch checked out here")
+				return
+			}
+		}
+
+		database, err := openDB(cfg)
+		if err != nil {
+			fmt.Printf("❌ Failed to open database: %v\n", err)
+			return
+		}
+		defer database.Close()
+
+		now := time.Now()
+		start, end, err := addInterval(database, now)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		session, err := manualSession(cfg, repo, branch, start, end)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		id, err := database.AddSession(session)
+		if errors.Is(err, db.ErrOverlappingSession) {
+			fmt.Printf("❌ %s – %s %v\n", start.Format("Mon 2006-01-02 15:04"), end.Format("15:04"), err)
+			return
+		}
+		if err != nil {
+			fmt.Printf("❌ Failed to add session: %v\n", err)
+			return
+		}
+
+		fmt.Printf("✍️  Added session %d on branch '%s': %s – %s (%s)\n", id, branch,
+			start.Format("Mon 2006-01-02 15:04"), end.Format("15:04"), tracker.FormatDuration(end.Sub(start)))
+	},
+}
+
+// addInterval resolves the flags to the start and end of the entry.
+func addInterval(database db.DB, now time.Time) (time.Time, time.Time, error) {
+	day, err := report.ParseDay(addOn, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	var start, end time.Time
+	if addFrom != "" {
+		if start, err = clockOn(day, addFrom); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --from: %w", err)
+		}
+	}
+	if addTo != "" {
+		if end, err = clockOn(day, addTo); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --to: %w", err)
+		}
+	}
+
+	switch {
+	case addDuration < 0:
+		return time.Time{}, time.Time{}, errors.New("--duration must be positive")
+	case addFrom != "" && addTo != "" && addDuration > 0:
+		return time.Time{}, time.Time{}, errors.New("use at most two of --from, --to and --duration")
+	case addFrom != "" && addTo != "":
+	case addFrom != "" && addDuration > 0:
+		end = start.Add(addDuration)
+	case addTo != "" && addDuration > 0:
+		start = end.Add(-addDuration)
+	case addDuration > 0 && day.Equal(report.DayRange(now).From):
+		end = now
+		start = now.Add(-addDuration)
+	case addDuration > 0:
+		if start, err = freeStart(database, day, now); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = start.Add(addDuration)
+	default:
+		return time.Time{}, time.Time{}, errors.New("give --from and --to, or --duration")
+	}
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, errors.New("the entry must end after it starts")
+	}
+	if end.After(now) {
+		return time.Time{}, time.Time{}, errors.New("cannot add time in the future")
+	}
+	return start, end, nil
+}
+
+// clockOn parses a HH:MM time of day on day.
+func clockOn(day time.Time, clock string) (time.Time, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a time like 09:00", clock)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location()), nil
+}
+
+// freeStart returns where the last session on day ended, or the start of
+// the working day if there is none.
+func freeStart(database db.DB, day, now time.Time) (time.Time, error) {
+	r := report.DayRange(day)
+	sessions, err := database.ListSessions(db.SessionFilter{From: r.From, To: r.To})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	start := day.Add(addDayStart)
+	for _, s := range sessions {
+		end := now
+		if s.Endtime != nil {
+			end = s.Endtime.In(day.Location())
+		}
+		if end.After(start) {
+			start = end
+		}
+	}
+	return start, nil
+}
+
+// manualSession builds the session for an entry on branch, with ticket and
+// project settings applied as if it had been tracked live.
+func manualSession(cfg *config.Config, repo git.Repository, branch string, start, end time.Time) (db.Session, error) {
+	tickets, err := cfg.TicketExtractor()
+	if err != nil {
+		return db.Session{}, err
+	}
+	ticketKey, _ := tickets.Extract(branch)
+
+	return db.Session{
+		Branch:    branch,
+		Ticket:    ticketKey,
+		Client:    cfg.Project.Client,
+		Project:   cfg.Project.Name,
+		RepoPath:  repo.Root,
+		RepoID:    repo.ID,
+		StartTime: start,
+		Endtime:   &end,
+		Origin:    db.OriginManual,
+		Tags:      append(append([]string{}, cfg.Project.Tags...), addTags...),
+	}, nil
+}
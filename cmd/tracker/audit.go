@@ -0,0 +1,112 @@
+// defines the audit command
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit [id]",
+	Short: "Show how sessions were edited or deleted by hand",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var id int64
+		if len(args) == 1 {
+			var err error
+			if id, err = parseSessionID(args[0]); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("❌ Failed to load config: %v\n", err)
+			return
+		}
+
+		database, err := openDB(cfg)
+		if err != nil {
+			fmt.Printf("❌ Failed to open database: %v\n", err)
+			return
+		}
+		defer database.Close()
+
+		entries, err := database.ListAudit(id)
+		if err != nil {
+			fmt.Printf("❌ Failed to load audit trail: %v\n", err)
+			return
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("💤 No sessions were changed by hand")
+			return
+		}
+
+		for _, e := range entries {
+			changedAt := e.ChangedAt.Local().Format("2006-01-02 15:04")
+			if e.After == nil {
+				fmt.Printf("%s 🗑️  deleted %s\n", changedAt, sessionLine(*e.Before))
+				continue
+			}
+			fmt.Printf("%s ✏️  edited #%d: %s\n", changedAt, e.SessionID, strings.Join(auditChanges(*e.Before, *e.After), ", "))
+		}
+	},
+}
+
+// auditChanges describes what an edit changed.
+func auditChanges(before, after db.Session) []string {
+	var changes []string
+	if before.Branch != after.Branch {
+		changes = append(changes, fmt.Sprintf("branch %s → %s", before.Branch, after.Branch))
+	}
+	if before.Ticket != after.Ticket {
+		changes = append(changes, fmt.Sprintf("ticket %q → %q", before.Ticket, after.Ticket))
+	}
+	if !before.StartTime.Equal(after.StartTime) {
+		changes = append(changes, fmt.Sprintf("start %s → %s", formatEditTime(&before.StartTime), formatEditTime(&after.StartTime)))
+	}
+	if !equalTime(before.Endtime, after.Endtime) {
+		changes = append(changes, fmt.Sprintf("end %s → %s", auditTime(before.Endtime), auditTime(after.Endtime)))
+	}
+	if !slices.Equal(before.Tags, after.Tags) {
+		changes = append(changes, fmt.Sprintf("tags [%s] → [%s]", strings.Join(before.Tags, ", "), strings.Join(after.Tags, ", ")))
+	}
+	if !equalPauses(before.Pauses, after.Pauses) {
+		changes = append(changes, fmt.Sprintf("pauses (%d → %d)", len(before.Pauses), len(after.Pauses)))
+	}
+	if len(changes) == 0 {
+		changes = append(changes, "no changes")
+	}
+	return changes
+}
+
+func auditTime(t *time.Time) string {
+	if t == nil {
+		return "running"
+	}
+	return formatEditTime(t)
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func equalPauses(a, b []db.Pause) bool {
+	return slices.EqualFunc(a, b, func(p, q db.Pause) bool {
+		return p.PauseStart.Equal(q.PauseStart) && equalTime(p.PauseEnd, q.PauseEnd) && p.Reason == q.Reason && p.Note == q.Note
+	})
+}
@@ -16,7 +16,7 @@ var completeCmd = &cobra.Command{
 	Use:   "complete",
 	Short: "Complete tracking",
 	Run: func(cmd *cobra.Command, args []string) {
-		tr, _, err := tracker.Init()
+		tr, _, err := initTracker()
 		if err != nil {
 			fmt.Printf("❌ Failed to initialize tracker: %v\n", err)
 			return
@@ -32,5 +32,6 @@ var completeCmd = &cobra.Command{
 
 		fmt.Printf("✅ Completed session on branch '%s'\n", status.Branch)
 		fmt.Printf("🕒 Total work time: %s\n", tracker.FormatDuration(status.TotalDuration))
+		printPauseBreakdown(status)
 	},
 }
@@ -0,0 +1,20 @@
+// defines the config command group
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and change settings in the config file",
+}
+
+// secretKeys are masked when settings are printed.
+var secretKeys = map[string]bool{
+	"jira.api_token": true,
+}
@@ -0,0 +1,37 @@
+// defines the config list command
+package main
+
+import (
+	"fmt"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configCmd.AddCommand(configListCmd)
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings with their effective values",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("❌ Failed to load config: %v\n", err)
+			return
+		}
+
+		printSettings(cfg)
+	},
+}
+
+func printSettings(cfg *config.Config) {
+	for _, key := range config.Keys() {
+		value, _ := cfg.Get(key)
+		if secretKeys[key] && value != "" {
+			value = "********"
+		}
+		fmt.Printf("%-24s = %s\n", key, value)
+	}
+}
@@ -0,0 +1,52 @@
+// defines the config set command
+package main
+
+import (
+	"fmt"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configCmd.AddCommand(configSetCmd)
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>...",
+	Short: "Change a setting in the config file",
+	Long:  "Change a setting in the config file. List settings such as ticket.patterns take one argument per element.",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := resolveConfigPath()
+		if err != nil {
+			fmt.Printf("❌ Failed to locate config file: %v\n", err)
+			return
+		}
+
+		// Only the key being set is written back, so defaults and
+		// environment overrides never leak into the file
+		cfg, err := config.LoadFile(path)
+		if err != nil {
+			fmt.Printf("❌ Failed to load config: %v\n", err)
+			return
+		}
+
+		if err := cfg.Set(args[0], args[1:]...); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		if err := cfg.Validate(); err != nil {
+			fmt.Printf("❌ Invalid value: %v\n", err)
+			return
+		}
+
+		if err := config.SaveKey(path, cfg, args[0]); err != nil {
+			fmt.Printf("❌ Failed to save config: %v\n", err)
+			return
+		}
+
+		fmt.Printf("✅ Set %s in %s\n", args[0], path)
+	},
+}
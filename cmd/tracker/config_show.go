@@ -0,0 +1,57 @@
+// defines the config show command
+package main
+
+import (
+	"fmt"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var showEffective bool
+
+func init() {
+	configShowCmd.Flags().BoolVar(&showEffective, "effective", false, "Show the merged config of this repository with the source of each value")
+	configCmd.AddCommand(configShowCmd)
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the config file, or the effective config with --effective",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !showEffective {
+			path, err := resolveConfigPath()
+			if err != nil {
+				fmt.Printf("❌ Failed to resolve config path: %v\n", err)
+				return
+			}
+
+			cfg, err := config.LoadFile(path)
+			if err != nil {
+				fmt.Printf("❌ Failed to load config: %v\n", err)
+				return
+			}
+
+			fmt.Printf("# %s\n", path)
+			printSettings(cfg)
+			return
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("❌ Failed to load config: %v\n", err)
+			return
+		}
+
+		if root := repoRoot(); root != "" {
+			fmt.Printf("# repository %s\n", root)
+		}
+		for _, key := range config.Keys() {
+			value, _ := cfg.Get(key)
+			if secretKeys[key] && value != "" {
+				value = "********"
+			}
+			fmt.Printf("%-24s = %-40s (%s)\n", key, value, cfg.Source(key))
+		}
+	},
+}
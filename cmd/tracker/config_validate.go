@@ -0,0 +1,31 @@
+// defines the config validate command
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config file and environment overrides for errors",
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := resolveConfigPath()
+		if err != nil {
+			fmt.Printf("❌ Failed to locate config file: %v\n", err)
+			return
+		}
+
+		if _, err := loadConfig(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		fmt.Printf("✅ Config is valid (%s)\n", path)
+	},
+}
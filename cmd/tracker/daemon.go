@@ -0,0 +1,87 @@
+// defines the daemon command group
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/daemon"
+	"github.com/spf13/cobra"
+)
+
+const (
+	daemonBinaryName   = "lofi-daemon"
+	daemonStartTimeout = 5 * time.Second
+	daemonStopTimeout  = 10 * time.Second
+)
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run the AFK detection daemon in the background",
+}
+
+// daemonPaths returns the PID and log file of the background daemon.
+func daemonPaths() (pidPath, logPath string, err error) {
+	pidPath, err = daemon.PIDPath()
+	if err != nil {
+		return "", "", err
+	}
+	logPath, err = daemon.LogPath()
+	if err != nil {
+		return "", "", err
+	}
+	return pidPath, logPath, nil
+}
+
+// daemonBinary finds lofi-daemon next to this executable, then on the PATH.
+func daemonBinary() (string, error) {
+	name := daemonBinaryName
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	if self, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(self), name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", errors.New("lofi-daemon not found next to lofi-tracker or on the PATH")
+	}
+	return path, nil
+}
+
+// startDaemon launches the daemon with the global flags of this invocation.
+func startDaemon() (int, string, error) {
+	pidPath, logPath, err := daemonPaths()
+	if err != nil {
+		return 0, "", err
+	}
+
+	binary, err := daemonBinary()
+	if err != nil {
+		return 0, "", err
+	}
+
+	var args []string
+	if configPath != "" {
+		args = append(args, "--config", configPath)
+	}
+	if dbPathFlag != "" {
+		args = append(args, "--db", dbPathFlag)
+	}
+
+	pid, err := daemon.Start(binary, args, pidPath, logPath, daemonStartTimeout)
+	return pid, logPath, err
+}
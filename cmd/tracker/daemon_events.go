@@ -0,0 +1,58 @@
+// defines the daemon events command
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/daemon"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/ipc"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	daemonCmd.AddCommand(daemonEventsCmd)
+}
+
+var daemonEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Follow session changes as the daemon makes them",
+	Run: func(cmd *cobra.Command, args []string) {
+		socketPath, err := daemon.SocketPath()
+		if err != nil {
+			fmt.Printf("❌ Failed to resolve control socket: %v\n", err)
+			return
+		}
+
+		client, err := ipc.Dial(socketPath, git.Repository{})
+		if err != nil {
+			fmt.Println("💤 Daemon is not running")
+			return
+		}
+		defer client.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		events, err := client.Subscribe(ctx)
+		if err != nil {
+			fmt.Printf("❌ Failed to subscribe: %v\n", err)
+			return
+		}
+
+		for event := range events {
+			line := fmt.Sprintf("%s %-9s", event.Time.Local().Format("15:04:05"), event.Type)
+			if s := event.Status; s != nil {
+				line += fmt.Sprintf(" %s (%s worked)", s.Branch, tracker.FormatDuration(s.TotalDuration))
+				if s.IsPaused {
+					line += fmt.Sprintf(", paused: %s", s.PauseReason)
+				}
+			}
+			fmt.Println(line)
+		}
+	},
+}
@@ -0,0 +1,40 @@
+// defines the daemon restart command
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/daemon"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	daemonCmd.AddCommand(daemonRestartCmd)
+}
+
+var daemonRestartCmd = &cobra.Command{
+	Use:   "restart",
+	Short: "Stop the daemon if it is running and start it again",
+	Run: func(cmd *cobra.Command, args []string) {
+		pidPath, _, err := daemonPaths()
+		if err != nil {
+			fmt.Printf("❌ Failed to resolve PID file: %v\n", err)
+			return
+		}
+
+		err = daemon.Stop(pidPath, daemonStopTimeout)
+		if err != nil && !errors.Is(err, daemon.ErrNotRunning) {
+			fmt.Printf("❌ Failed to stop daemon: %v\n", err)
+			return
+		}
+
+		pid, logPath, err := startDaemon()
+		if err != nil {
+			fmt.Printf("❌ Failed to start daemon: %v\n", err)
+			return
+		}
+
+		fmt.Printf("✅ Daemon restarted with pid %d, logging to %s\n", pid, logPath)
+	},
+}
@@ -0,0 +1,26 @@
+// defines the daemon start command
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	daemonCmd.AddCommand(daemonStartCmd)
+}
+
+var daemonStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the daemon in the background",
+	Run: func(cmd *cobra.Command, args []string) {
+		pid, logPath, err := startDaemon()
+		if err != nil {
+			fmt.Printf("❌ Failed to start daemon: %v\n", err)
+			return
+		}
+
+		fmt.Printf("✅ Daemon started with pid %d, logging to %s\n", pid, logPath)
+	},
+}
@@ -0,0 +1,44 @@
+// defines the daemon status command
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/daemon"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	daemonCmd.AddCommand(daemonStatusCmd)
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the daemon is running",
+	Run: func(cmd *cobra.Command, args []string) {
+		pidPath, logPath, err := daemonPaths()
+		if err != nil {
+			fmt.Printf("❌ Failed to resolve PID file: %v\n", err)
+			return
+		}
+
+		status, err := daemon.ReadStatus(pidPath)
+		if err != nil {
+			fmt.Printf("❌ Failed to read PID file: %v\n", err)
+			return
+		}
+
+		switch {
+		case status.Running:
+			fmt.Printf("🟢 Daemon running with pid %d for %s\n", status.PID, tracker.FormatDuration(time.Since(status.Since)))
+			fmt.Printf("📄 Log: %s\n", logPath)
+		case status.Stale:
+			fmt.Printf("⚠️ Daemon is not running, but left a stale PID file (pid %d) at %s\n", status.PID, pidPath)
+			fmt.Println("   It is removed by the next 'daemon start'")
+		default:
+			fmt.Println("💤 Daemon is not running")
+		}
+	},
+}
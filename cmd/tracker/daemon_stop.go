@@ -0,0 +1,38 @@
+// defines the daemon stop command
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/daemon"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	daemonCmd.AddCommand(daemonStopCmd)
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the background daemon",
+	Run: func(cmd *cobra.Command, args []string) {
+		pidPath, _, err := daemonPaths()
+		if err != nil {
+			fmt.Printf("❌ Failed to resolve PID file: %v\n", err)
+			return
+		}
+
+		err = daemon.Stop(pidPath, daemonStopTimeout)
+		if errors.Is(err, daemon.ErrNotRunning) {
+			fmt.Println("💤 Daemon is not running")
+			return
+		}
+		if err != nil {
+			fmt.Printf("❌ Failed to stop daemon: %v\n", err)
+			return
+		}
+
+		fmt.Println("🛑 Daemon stopped")
+	},
+}
@@ -0,0 +1,28 @@
+// defines the db command group
+package main
+
+import (
+	"github.com/impactj90/lofi-tracker/cmd/internal/config"
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+}
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Inspect and maintain the tracking database",
+}
+
+// openDB opens the tracking database directly, for commands that read history
+// and therefore must not depend on the current directory being a repository.
+func openDB(cfg *config.Config) (db.DB, error) {
+	dbPath, err := cfg.ResolvedDBPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return db.NewSQLiteDB(dbPath)
+}
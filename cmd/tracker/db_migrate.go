@@ -0,0 +1,77 @@
+// defines the db migrate command
+package main
+
+import (
+	"fmt"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var migrateStatusOnly bool
+
+func init() {
+	dbMigrateCmd.Flags().BoolVar(&migrateStatusOnly, "status", false, "Show applied and pending migrations without applying them")
+	dbCmd.AddCommand(dbMigrateCmd)
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending database migrations",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("❌ Failed to load config: %v\n", err)
+			return
+		}
+
+		dbPath, err := cfg.ResolvedDBPath()
+		if err != nil {
+			fmt.Printf("❌ Failed to get database path: %v\n", err)
+			return
+		}
+
+		if migrateStatusOnly {
+			states, err := db.MigrationStatus(dbPath)
+			if err != nil {
+				fmt.Printf("❌ Failed to read migration status: %v\n", err)
+				return
+			}
+
+			newer := false
+			for _, state := range states {
+				switch {
+				case state.Unknown:
+					newer = true
+					fmt.Printf("⚠️ %04d %-30s unknown to this binary\n", state.Version, state.Name)
+				case state.AppliedAt != nil:
+					fmt.Printf("✅ %04d %-30s applied %s\n", state.Version, state.Name, state.AppliedAt.Local().Format("2006-01-02 15:04"))
+				case state.Applied:
+					fmt.Printf("✅ %04d %-30s applied\n", state.Version, state.Name)
+				default:
+					fmt.Printf("⏳ %04d %-30s pending\n", state.Version, state.Name)
+				}
+			}
+
+			if newer {
+				fmt.Printf("❌ %v\n", db.ErrDatabaseVersionTooNew)
+			}
+			return
+		}
+
+		applied, err := db.Migrate(dbPath)
+		if err != nil {
+			fmt.Printf("❌ Failed to migrate database: %v\n", err)
+			return
+		}
+
+		if len(applied) == 0 {
+			fmt.Println("✅ Database is up to date")
+			return
+		}
+
+		for _, m := range applied {
+			fmt.Printf("✅ Applied %04d %s\n", m.Version, m.Name)
+		}
+	},
+}
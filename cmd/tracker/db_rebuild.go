@@ -0,0 +1,58 @@
+// defines the db rebuild command
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var rebuildDryRun bool
+
+func init() {
+	dbRebuildCmd.Flags().BoolVar(&rebuildDryRun, "dry-run", false, "Only report where the tables differ from the event log")
+	dbCmd.AddCommand(dbRebuildCmd)
+}
+
+var dbRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Rebuild sessions and pauses from the event log",
+	Long: `Every change to sessions and pauses is appended to an event log in the same
+transaction. This replays the log into fresh session and pause tables and
+reports any difference to the tables as they were.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("❌ Failed to load config: %v\n", err)
+			return
+		}
+
+		database, err := openDB(cfg)
+		if err != nil {
+			fmt.Printf("❌ Failed to open database: %v\n", err)
+			return
+		}
+		defer database.Close()
+
+		result, err := database.RebuildFromEvents(rebuildDryRun)
+		if err != nil {
+			fmt.Printf("❌ Failed to rebuild from events: %v\n", err)
+			return
+		}
+
+		fmt.Printf("🔁 Replayed %d events into %d sessions\n", result.Events, result.Sessions)
+		if len(result.Differences) == 0 {
+			fmt.Println("✅ Sessions and pauses match the event log")
+			return
+		}
+
+		for _, d := range result.Differences {
+			fmt.Printf("⚠️  %s\n", d)
+		}
+		if rebuildDryRun {
+			fmt.Println("Run without --dry-run to replace the tables with the event log")
+			return
+		}
+		fmt.Println("✅ Replaced the tables with the event log")
+	},
+}
@@ -0,0 +1,61 @@
+// defines the delete command
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var deleteYes bool
+
+func init() {
+	deleteCmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "Delete without asking")
+	rootCmd.AddCommand(deleteCmd)
+}
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a session and its pauses",
+	Long: `Deletes a session and its pauses. A copy is kept in the audit trail shown by
+'lofi-tracker audit'. Session IDs are listed by 'lofi-tracker sessions'.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := parseSessionID(args[0])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("❌ Failed to load config: %v\n", err)
+			return
+		}
+
+		database, err := openDB(cfg)
+		if err != nil {
+			fmt.Printf("❌ Failed to open database: %v\n", err)
+			return
+		}
+		defer database.Close()
+
+		session, err := database.GetSession(id)
+		if err != nil {
+			fmt.Printf("❌ Failed to load session %d: %v\n", id, err)
+			return
+		}
+
+		if !deleteYes && !confirm(fmt.Sprintf("Delete %s?", sessionLine(*session))) {
+			fmt.Println("↩️  Nothing deleted")
+			return
+		}
+
+		if err := database.DeleteSession(id); err != nil {
+			fmt.Printf("❌ Failed to delete session %d: %v\n", id, err)
+			return
+		}
+
+		fmt.Printf("🗑️  Deleted %s\n", sessionLine(*session))
+	},
+}
@@ -0,0 +1,44 @@
+// defines the doctor idle command
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/afk"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	doctorCmd.AddCommand(doctorIdleCmd)
+}
+
+var doctorIdleCmd = &cobra.Command{
+	Use:   "idle",
+	Short: "Show which idle time providers work and what they report",
+	Long: `Asks every idle time provider of this platform in the order the daemon tries
+them. The daemon uses the first one that works and falls back to the next when
+it fails. Providers are checked in this shell's environment, which may differ
+from the daemon's (e.g. $DISPLAY or $DBUS_SESSION_BUS_ADDRESS).`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		used := ""
+		for _, d := range afk.DiagnoseIdle() {
+			if d.Err != nil {
+				fmt.Printf("❌ %-12s %s\n", d.Name, strings.ReplaceAll(d.Err.Error(), "\n", "; "))
+				continue
+			}
+			fmt.Printf("✅ %-12s idle for %s\n", d.Name, d.IdleTime.Round(time.Second))
+			if used == "" {
+				used = d.Name
+			}
+		}
+
+		if used == "" {
+			fmt.Println("\n⚠️ No idle time provider works, the daemon cannot detect when you are away")
+			return
+		}
+		fmt.Printf("\n👉 The daemon would use %s\n", used)
+	},
+}
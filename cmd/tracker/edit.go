@@ -0,0 +1,404 @@
+// defines the edit command
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// editTimeLayout is how times are shown in the editor; editTime also accepts
+// it without seconds and RFC 3339.
+const editTimeLayout = "2006-01-02 15:04:05"
+
+var (
+	editStart  string
+	editEnd    string
+	editBranch string
+)
+
+func init() {
+	editCmd.Flags().StringVar(&editStart, "start", "", "New start, e.g. 09:00 on the session's day or '2026-10-17 09:00'")
+	editCmd.Flags().StringVar(&editEnd, "end", "", "New end, e.g. 18:00 on the session's day or '2026-10-17 18:00'")
+	editCmd.Flags().StringVar(&editBranch, "branch", "", "Book the session on another branch")
+	rootCmd.AddCommand(editCmd)
+}
+
+var editCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Correct the times or branch of a session",
+	Long: `Changes a session, e.g. one that ran overnight because it was never completed:
+
+  lofi-tracker edit 42 --end 18:30
+  lofi-tracker edit 42 --branch feature/ABC-7
+
+Pauses outside the new times are cut off. Without flags the session and its
+pauses open in $EDITOR as YAML. Session IDs are listed by 'lofi-tracker sessions'.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := parseSessionID(args[0])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("❌ Failed to load config: %v\n", err)
+			return
+		}
+
+		database, err := openDB(cfg)
+		if err != nil {
+			fmt.Printf("❌ Failed to open database: %v\n", err)
+			return
+		}
+		defer database.Close()
+
+		session, err := database.GetSession(id)
+		if err != nil {
+			fmt.Printf("❌ Failed to load session %d: %v\n", id, err)
+			return
+		}
+
+		var edited db.Session
+		if editStart == "" && editEnd == "" && editBranch == "" {
+			edited, err = editInEditor(*session)
+		} else {
+			edited, err = editWithFlags(*session)
+		}
+		if errors.Is(err, errEditCanceled) {
+			fmt.Println("↩️  Nothing changed")
+			return
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		// A session moved to another branch gets that branch's ticket, unless
+		// the ticket was edited as well
+		if edited.Branch != session.Branch && edited.Ticket == session.Ticket {
+			tickets, err := cfg.TicketExtractor()
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+			edited.Ticket, _ = tickets.Extract(edited.Branch)
+		}
+
+		if err := database.UpdateSession(edited); err != nil {
+			fmt.Printf("❌ Failed to update session %d: %v\n", id, err)
+			return
+		}
+
+		fmt.Printf("✏️  Updated %s\n", sessionLine(edited))
+	},
+}
+
+var errEditCanceled = errors.New("edit canceled")
+
+// editWithFlags applies --start, --end and --branch, cutting off pauses that
+// fall outside the new times.
+func editWithFlags(session db.Session) (db.Session, error) {
+	day := session.StartTime.Local()
+
+	if editStart != "" {
+		start, err := editTime(editStart, day)
+		if err != nil {
+			return db.Session{}, fmt.Errorf("invalid --start: %w", err)
+		}
+		session.StartTime = start
+	}
+	if editEnd != "" {
+		end, err := editTime(editEnd, day)
+		if err != nil {
+			return db.Session{}, fmt.Errorf("invalid --end: %w", err)
+		}
+		if end.After(time.Now()) {
+			return db.Session{}, errors.New("a session cannot end in the future")
+		}
+		session.Endtime = &end
+	}
+	if editBranch != "" {
+		session.Branch = editBranch
+	}
+
+	if session.Endtime != nil && !session.Endtime.After(session.StartTime) {
+		return db.Session{}, db.ErrInvalidSessionTimes
+	}
+
+	var pauses []db.Pause
+	for _, p := range session.Pauses {
+		if session.Endtime != nil && !p.PauseStart.Before(*session.Endtime) {
+			continue
+		}
+		if p.PauseEnd != nil && !p.PauseEnd.After(session.StartTime) {
+			continue
+		}
+		if p.PauseStart.Before(session.StartTime) {
+			p.PauseStart = session.StartTime
+		}
+		if session.Endtime != nil && (p.PauseEnd == nil || p.PauseEnd.After(*session.Endtime)) {
+			end := *session.Endtime
+			p.PauseEnd = &end
+		}
+		pauses = append(pauses, p)
+	}
+	session.Pauses = pauses
+
+	return session, nil
+}
+
+// editTime parses a time of day on day, or a full date and time.
+func editTime(value string, day time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{editTimeLayout, "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), 0, day.Location()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a time like 09:00 or 2026-10-17 09:00", value)
+}
+
+// sessionDoc is the YAML form of a session in the editor.
+type sessionDoc struct {
+	Branch string     `yaml:"branch"`
+	Ticket string     `yaml:"ticket"`
+	Start  string     `yaml:"start"`
+	End    string     `yaml:"end"`
+	Tags   []string   `yaml:"tags"`
+	Pauses []pauseDoc `yaml:"pauses"`
+}
+
+type pauseDoc struct {
+	ID     int64  `yaml:"id,omitempty"`
+	Start  string `yaml:"start"`
+	End    string `yaml:"end"`
+	Reason string `yaml:"reason"`
+	Note   string `yaml:"note,omitempty"`
+	// Decision keeps or discards the time of an AFK pause
+	Decision string `yaml:"decision,omitempty"`
+	Activity string `yaml:"activity,omitempty"`
+}
+
+const editHeader = `# Session %d. Times are local; leave "end" empty while the session or pause
+# is still running. Save and quit to apply, or empty the file to cancel.
+`
+
+// editInEditor opens session as YAML in the user's editor until it parses,
+// or the user gives up.
+func editInEditor(session db.Session) (db.Session, error) {
+	original, err := encodeSessionDoc(session)
+	if err != nil {
+		return db.Session{}, err
+	}
+
+	text := original
+	for {
+		text, err = runEditor(text)
+		if err != nil {
+			return db.Session{}, err
+		}
+		if bytes.Equal(text, original) || len(bytes.TrimSpace(stripComments(text))) == 0 {
+			return db.Session{}, errEditCanceled
+		}
+
+		edited, err := decodeSessionDoc(text, session)
+		if err == nil {
+			err = checkNotInFuture(edited, time.Now())
+		}
+		if err == nil {
+			err = db.ValidateSession(edited)
+		}
+		if err == nil {
+			return edited, nil
+		}
+
+		fmt.Printf("❌ %v\n", err)
+		if !confirm("Edit again?") {
+			return db.Session{}, errEditCanceled
+		}
+	}
+}
+
+func encodeSessionDoc(session db.Session) ([]byte, error) {
+	doc := sessionDoc{
+		Branch: session.Branch,
+		Ticket: session.Ticket,
+		Start:  formatEditTime(&session.StartTime),
+		End:    formatEditTime(session.Endtime),
+		Tags:   session.Tags,
+	}
+	for _, p := range session.Pauses {
+		doc.Pauses = append(doc.Pauses, pauseDoc{
+			ID:       p.ID,
+			Start:    formatEditTime(&p.PauseStart),
+			End:      formatEditTime(p.PauseEnd),
+			Reason:   string(p.Reason),
+			Note:     p.Note,
+			Decision: string(p.Decision),
+			Activity: p.Activity,
+		})
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, editHeader, session.ID)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeSessionDoc applies an edited document to session.
+func decodeSessionDoc(data []byte, session db.Session) (db.Session, error) {
+	var doc sessionDoc
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return db.Session{}, fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	if strings.TrimSpace(doc.Branch) == "" {
+		return db.Session{}, errors.New("branch must not be empty")
+	}
+	session.Branch = strings.TrimSpace(doc.Branch)
+	session.Ticket = strings.TrimSpace(doc.Ticket)
+	session.Tags = doc.Tags
+
+	day := session.StartTime.Local()
+	start, err := editTime(doc.Start, day)
+	if err != nil {
+		return db.Session{}, fmt.Errorf("start: %w", err)
+	}
+	session.StartTime = start
+	if session.Endtime, err = optionalEditTime(doc.End, day); err != nil {
+		return db.Session{}, fmt.Errorf("end: %w", err)
+	}
+
+	session.Pauses = nil
+	for i, p := range doc.Pauses {
+		reason, err := db.ParsePauseReason(p.Reason)
+		if err != nil {
+			return db.Session{}, fmt.Errorf("pause %d: %w", i+1, err)
+		}
+		decision, err := db.ParseIdleDecision(p.Decision)
+		if err != nil {
+			return db.Session{}, fmt.Errorf("pause %d: %w", i+1, err)
+		}
+		pause := db.Pause{ID: p.ID, SessionID: session.ID, Reason: reason, IsAfk: reason.IsAfk(), Note: p.Note, Decision: decision, Activity: strings.TrimSpace(p.Activity)}
+		if pause.PauseStart, err = editTime(p.Start, day); err != nil {
+			return db.Session{}, fmt.Errorf("pause %d start: %w", i+1, err)
+		}
+		if pause.PauseEnd, err = optionalEditTime(p.End, day); err != nil {
+			return db.Session{}, fmt.Errorf("pause %d end: %w", i+1, err)
+		}
+		session.Pauses = append(session.Pauses, pause)
+	}
+
+	return session, nil
+}
+
+// checkNotInFuture rejects edited times after now, as --end does.
+func checkNotInFuture(session db.Session, now time.Time) error {
+	if session.StartTime.After(now) {
+		return errors.New("a session cannot start in the future")
+	}
+	if session.Endtime != nil && session.Endtime.After(now) {
+		return errors.New("a session cannot end in the future")
+	}
+	for i, p := range session.Pauses {
+		if p.PauseStart.After(now) {
+			return fmt.Errorf("pause %d cannot start in the future", i+1)
+		}
+		if p.PauseEnd != nil && p.PauseEnd.After(now) {
+			return fmt.Errorf("pause %d cannot end in the future", i+1)
+		}
+	}
+	return nil
+}
+
+func formatEditTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format(editTimeLayout)
+}
+
+func optionalEditTime(value string, day time.Time) (*time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	t, err := editTime(value, day)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// stripComments drops the comment lines of a YAML document.
+func stripComments(data []byte) []byte {
+	var out [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if !bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			out = append(out, line)
+		}
+	}
+	return bytes.Join(out, []byte("\n"))
+}
+
+// runEditor lets the user edit text in $VISUAL or $EDITOR, falling back to vi.
+func runEditor(text []byte) ([]byte, error) {
+	f, err := os.CreateTemp("", "lofi-tracker-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(text); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor may come with arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], f.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+
+	return os.ReadFile(f.Name())
+}
@@ -0,0 +1,107 @@
+// defines the idle command group
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(idleCmd)
+}
+
+var idleCmd = &cobra.Command{
+	Use:   "idle",
+	Short: "Keep or discard the time you were away from the keyboard",
+	Long: `When the daemon resumes a session after you were AFK, the idle time counts
+as paused until you decide: keep it as work, say at the whiteboard or on a
+call, or discard it. "status" asks about idle time awaiting a decision.`,
+}
+
+// decideIdle keeps or discards the latest idle time that awaits a decision,
+// also when its session was completed in the meantime.
+func decideIdle(decision db.IdleDecision, activity string) {
+	tr, _, err := initTracker()
+	if err != nil {
+		fmt.Printf("❌ Failed to initialize tracker: %v\n", err)
+		return
+	}
+
+	defer tr.Close()
+
+	pending, err := tr.PendingIdle()
+	if err != nil {
+		fmt.Printf("❌ Failed to look up idle time: %v\n", err)
+		return
+	}
+	if len(pending) == 0 {
+		fmt.Println("🤷 No idle time awaits a decision")
+		return
+	}
+
+	p := pending[len(pending)-1]
+	if err := tr.DecideIdle(p.ID, decision, activity); err != nil {
+		fmt.Printf("❌ Failed to decide on idle time: %v\n", err)
+		return
+	}
+
+	switch {
+	case decision == db.IdleDiscarded:
+		fmt.Printf("🗑️  Discarded %s of idle time\n", idleLength(p))
+	case activity != "":
+		fmt.Printf("✅ Kept %s of idle time as work (%s)\n", idleLength(p), activity)
+	default:
+		fmt.Printf("✅ Kept %s of idle time as work\n", idleLength(p))
+	}
+}
+
+// askAboutIdle asks whether the idle time awaiting a decision was work, when
+// there is a terminal to ask on. Idle time of completed sessions is included.
+func askAboutIdle(tr tracker.Tracker) {
+	pending, err := tr.PendingIdle()
+	if err != nil {
+		fmt.Printf("❌ Failed to look up idle time: %v\n", err)
+		return
+	}
+
+	if !isTerminal(os.Stdin) {
+		for _, p := range pending {
+			fmt.Printf("💤 You were idle %s since %s, keep it as work with 'lofi-tracker idle keep'\n", idleLength(p), idleSince(p))
+		}
+		return
+	}
+
+	for _, p := range pending {
+		keep, answered := ask(fmt.Sprintf("💤 You were idle %s since %s — keep as work?", idleLength(p), idleSince(p)))
+		if !answered {
+			// Nobody there to answer; ask again next time
+			return
+		}
+		decision := db.IdleDiscarded
+		if keep {
+			decision = db.IdleKept
+		}
+		if err := tr.DecideIdle(p.ID, decision, ""); err != nil {
+			fmt.Printf("❌ Failed to decide on idle time: %v\n", err)
+			return
+		}
+	}
+}
+
+func idleLength(p db.Pause) string {
+	return tracker.FormatDuration(p.PauseEnd.Sub(p.PauseStart))
+}
+
+// idleSince says when idle time began, with the day unless it was today.
+func idleSince(p db.Pause) string {
+	start := p.PauseStart.Local()
+	if start.Format(time.DateOnly) == time.Now().Format(time.DateOnly) {
+		return start.Format("15:04")
+	}
+	return start.Format("Mon 15:04")
+}
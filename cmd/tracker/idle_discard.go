@@ -0,0 +1,20 @@
+// defines the idle discard command
+package main
+
+import (
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	idleCmd.AddCommand(idleDiscardCmd)
+}
+
+var idleDiscardCmd = &cobra.Command{
+	Use:   "discard",
+	Short: "Leave the latest idle time a pause",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		decideIdle(db.IdleDiscarded, "")
+	},
+}
@@ -0,0 +1,24 @@
+// defines the idle keep command
+package main
+
+import (
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	idleCmd.AddCommand(idleKeepCmd)
+}
+
+var idleKeepCmd = &cobra.Command{
+	Use:   "keep [activity]",
+	Short: "Count the latest idle time as work, optionally labelled, e.g. whiteboard",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		activity := ""
+		if len(args) == 1 {
+			activity = args[0]
+		}
+		decideIdle(db.IdleKept, activity)
+	},
+}
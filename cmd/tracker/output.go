@@ -0,0 +1,123 @@
+// shared output helpers for the commands
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+)
+
+// printPauseBreakdown prints how much of a session was spent paused, split by
+// the reason of each pause.
+func printPauseBreakdown(status tracker.SessionStatus) {
+	printPaused(status.PausedDuration, status.PausedByReason)
+}
+
+func printPaused(total time.Duration, byReason map[db.PauseReason]time.Duration) {
+	if total <= 0 {
+		return
+	}
+
+	reasons := make([]string, 0, len(byReason))
+	for reason := range byReason {
+		reasons = append(reasons, string(reason))
+	}
+	sort.Strings(reasons)
+
+	parts := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		d := byReason[db.PauseReason(reason)]
+		parts = append(parts, fmt.Sprintf("%s %s", reason, tracker.FormatDuration(d)))
+	}
+
+	fmt.Printf("☕ Paused: %s (%s)\n", tracker.FormatDuration(total), strings.Join(parts, ", "))
+}
+
+// printKept prints how much idle time was kept as work, split by activity.
+func printKept(status tracker.SessionStatus) {
+	if status.KeptIdleDuration <= 0 {
+		return
+	}
+
+	activities := make([]string, 0, len(status.KeptByActivity))
+	for activity := range status.KeptByActivity {
+		activities = append(activities, activity)
+	}
+	sort.Strings(activities)
+
+	parts := make([]string, 0, len(activities))
+	for _, activity := range activities {
+		label := activity
+		if label == "" {
+			label = "unlabelled"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", label, tracker.FormatDuration(status.KeptByActivity[activity])))
+	}
+
+	fmt.Printf("🧠 Idle time kept as work: %s (%s)\n", tracker.FormatDuration(status.KeptIdleDuration), strings.Join(parts, ", "))
+}
+
+// sessionLine describes a session on one line, with its ID for edit and
+// delete.
+func sessionLine(s db.Session) string {
+	start := s.StartTime.Local()
+	end := "now"
+	if s.Endtime != nil {
+		end = s.Endtime.Local().Format("15:04")
+		if !sameDay(start, s.Endtime.Local()) {
+			end = s.Endtime.Local().Format("Mon 2006-01-02 15:04")
+		}
+	}
+	return fmt.Sprintf("#%d %s – %s %s", s.ID, start.Format("Mon 2006-01-02 15:04"), end, s.Branch)
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// confirm asks a yes/no question on the terminal; anything but y or yes is no.
+func confirm(question string) bool {
+	yes, _ := ask(question)
+	return yes
+}
+
+// ask is confirm that also reports whether an answer came at all, rather
+// than the end of the input.
+func ask(question string) (yes, answered bool) {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		return false, false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, true
+	}
+	return false, true
+}
+
+// isTerminal reports whether f is a terminal someone can answer on.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// parseSessionID parses a session ID as printed by sessionLine, with or
+// without the leading #.
+func parseSessionID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(arg, "#"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid session ID %q", arg)
+	}
+	return id, nil
+}
@@ -3,11 +3,19 @@ package main
 
 import (
 	"fmt"
-	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
 	"github.com/spf13/cobra"
 )
 
+var (
+	pauseReason string
+	pauseNote   string
+)
+
 func init() {
+	pauseCmd.Flags().StringVar(&pauseReason, "reason", string(db.PauseReasonManual), "Why you are pausing, e.g. lunch or meeting")
+	pauseCmd.Flags().StringVar(&pauseNote, "note", "", "Optional free-text note stored with the pause")
 	rootCmd.AddCommand(pauseCmd)
 }
 
@@ -15,7 +23,13 @@ var pauseCmd = &cobra.Command{
 	Use:   "pause",
 	Short: "Pause tracking",
 	Run: func(cmd *cobra.Command, args []string) {
-		tr, branchName, err := tracker.Init()
+		reason, err := db.ParsePauseReason(pauseReason)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		tr, branchName, err := initTracker()
 		if err != nil {
 			fmt.Printf("❌ Failed to initialize tracker: %v\n", err)
 			return
@@ -23,12 +37,12 @@ var pauseCmd = &cobra.Command{
 
 		defer tr.Close()
 
-		err = tr.Pause(false)
+		err = tr.Pause(reason, pauseNote)
 		if err != nil {
 			fmt.Printf("❌ Failed to pause tracking: %v\n", err)
 			return
 		}
 
-		fmt.Printf("⏸️  Session paused on branch '%s'\n", branchName)
+		fmt.Printf("⏸️  Session paused on branch '%s' (%s)\n", branchName, reason)
 	},
 }
@@ -0,0 +1,258 @@
+// defines the report command
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/config"
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/report"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+	"github.com/impactj90/lofi-tracker/cmd/internal/weekday"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportDay       bool
+	reportWeek      bool
+	reportMonth     bool
+	reportFrom      string
+	reportTo        string
+	reportWeekStart string
+	reportRepo      string
+	reportBranch    string
+	reportTag       string
+	reportTicket    string
+	reportGroupBy   string
+)
+
+func init() {
+	reportCmd.Flags().BoolVar(&reportDay, "day", false, "Report on today")
+	reportCmd.Flags().BoolVar(&reportWeek, "week", false, "Report on the current week")
+	reportCmd.Flags().BoolVar(&reportMonth, "month", false, "Report on the current month")
+	reportCmd.Flags().StringVar(&reportFrom, "from", "", "First day of a custom range (YYYY-MM-DD)")
+	reportCmd.Flags().StringVar(&reportTo, "to", "", "Last day of a custom range (YYYY-MM-DD), defaults to today")
+	reportCmd.Flags().StringVar(&reportWeekStart, "week-start", "monday", "Day the week starts on (default from report.week_start)")
+	reportCmd.Flags().StringVar(&reportRepo, "repo", "", "Only include sessions of this repository (path, id, or '.' for the current one)")
+	reportCmd.Flags().StringVar(&reportBranch, "branch", "", "Only include branches matching this glob, e.g. 'feature/*'")
+	reportCmd.Flags().StringVar(&reportTag, "tag", "", "Only include sessions with this tag")
+	reportCmd.Flags().StringVar(&reportTicket, "ticket", "", "Only include sessions of this ticket")
+	reportCmd.Flags().StringVar(&reportGroupBy, "by", "branch", "Group time by 'branch', 'ticket', 'project' or 'client' (default from report.group_by)")
+	reportCmd.MarkFlagsMutuallyExclusive("day", "week", "month", "from")
+	rootCmd.AddCommand(reportCmd)
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize tracked time per branch and per day",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("❌ Failed to load config: %v\n", err)
+			return
+		}
+		if !cmd.Flags().Changed("week-start") {
+			reportWeekStart = cfg.Report.WeekStart
+		}
+		if !cmd.Flags().Changed("by") {
+			reportGroupBy = cfg.Report.GroupBy
+		}
+
+		r, err := reportRange(time.Now(), cfg.Report.Range)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		groupBy, err := reportGrouping(cfg)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		repo, err := reportRepoFilter()
+		if err != nil {
+			fmt.Printf("❌ Failed to resolve repository: %v\n", err)
+			return
+		}
+
+		database, err := openDB(cfg)
+		if err != nil {
+			fmt.Printf("❌ Failed to open database: %v\n", err)
+			return
+		}
+		defer database.Close()
+
+		sessions, err := database.ListSessions(db.SessionFilter{
+			From:   r.From,
+			To:     r.To,
+			Branch: reportBranch,
+			Ticket: strings.ToUpper(reportTicket),
+			Repo:   repo,
+			Tag:    reportTag,
+		})
+		if err != nil {
+			fmt.Printf("❌ Failed to load sessions: %v\n", err)
+			return
+		}
+
+		printReport(report.Build(sessions, r, time.Now(), groupBy))
+	},
+}
+
+// reportRange returns the range selected by the flags, or defaultRange (day,
+// week or month) when none is given.
+func reportRange(now time.Time, defaultRange string) (report.Range, error) {
+	weekStart, err := weekday.Parse(reportWeekStart)
+	if err != nil {
+		return report.Range{}, err
+	}
+	if reportTo != "" && reportFrom == "" {
+		return report.Range{}, errors.New("--to requires --from")
+	}
+
+	switch {
+	case reportDay:
+		return report.DayRange(now), nil
+	case reportWeek:
+		return report.WeekRange(now, weekStart), nil
+	case reportMonth:
+		return report.MonthRange(now), nil
+	case reportFrom != "":
+		from, err := time.ParseInLocation(time.DateOnly, reportFrom, time.Local)
+		if err != nil {
+			return report.Range{}, fmt.Errorf("invalid --from date: %w", err)
+		}
+		to := now
+		if reportTo != "" {
+			to, err = time.ParseInLocation(time.DateOnly, reportTo, time.Local)
+			if err != nil {
+				return report.Range{}, fmt.Errorf("invalid --to date: %w", err)
+			}
+		}
+		return report.DatesRange(from, to)
+	case defaultRange == "week":
+		return report.WeekRange(now, weekStart), nil
+	case defaultRange == "month":
+		return report.MonthRange(now), nil
+	default:
+		return report.DayRange(now), nil
+	}
+}
+
+// reportGrouping returns how --by groups sessions. Sessions recorded before
+// tickets were stored get their ticket derived from the branch name here.
+func reportGrouping(cfg *config.Config) (report.GroupBy, error) {
+	switch reportGroupBy {
+	case "", "branch":
+		return report.ByBranch, nil
+	case "ticket":
+		tickets, err := cfg.TicketExtractor()
+		if err != nil {
+			return nil, err
+		}
+		return report.ByTicket(func(s db.Session) string {
+			key := s.Ticket
+			if key == "" {
+				key, _ = tickets.Extract(s.Branch)
+			}
+			return tickets.Bucket(key)
+		}), nil
+	case "project":
+		return report.ByProject, nil
+	case "client":
+		return report.ByClient, nil
+	default:
+		return nil, fmt.Errorf("unknown grouping %q, use 'branch', 'ticket', 'project' or 'client'", reportGroupBy)
+	}
+}
+
+// reportRepoFilter resolves --repo to a repository ID or top-level path.
+func reportRepoFilter() (string, error) {
+	switch reportRepo {
+	case "":
+		return "", nil
+	case ".":
+		repo, err := git.GetCurrentRepository()
+		if err != nil {
+			return "", err
+		}
+		return repo.ID, nil
+	}
+
+	if info, err := os.Stat(reportRepo); err == nil && info.IsDir() {
+		return filepath.Abs(reportRepo)
+	}
+	return reportRepo, nil
+}
+
+func printReport(rep report.Report) {
+	last := rep.Range.To.AddDate(0, 0, -1)
+	if last.Equal(rep.Range.From) {
+		fmt.Printf("📊 Report for %s\n", rep.Range.From.Format("Mon 2006-01-02"))
+	} else {
+		fmt.Printf("📊 Report for %s – %s\n", rep.Range.From.Format("Mon 2006-01-02"), last.Format("Mon 2006-01-02"))
+	}
+
+	if rep.Total == 0 {
+		fmt.Println("💤 No time tracked in this range")
+		return
+	}
+
+	fmt.Println()
+	switch reportGroupBy {
+	case "ticket", "project", "client":
+		fmt.Printf("Per %s:\n", reportGroupBy)
+	default:
+		fmt.Println("Per branch:")
+	}
+	for _, e := range rep.Entries {
+		fmt.Printf("  %-40s %8s%s\n", entryLabel(e, rep.Entries), tracker.FormatDuration(e.Duration), manualNote(e.Manual))
+	}
+
+	if len(rep.Days) > 1 {
+		fmt.Println()
+		fmt.Println("Per day:")
+		for _, day := range rep.Days {
+			fmt.Printf("  %-40s %8s%s\n", day.Date.Format("Mon 2006-01-02"), tracker.FormatDuration(day.Duration), manualNote(day.Manual))
+			for _, e := range day.Entries {
+				fmt.Printf("    %-38s %8s%s\n", entryLabel(e, rep.Entries), tracker.FormatDuration(e.Duration), manualNote(e.Manual))
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("🕒 Total work time: %s\n", tracker.FormatDuration(rep.Total))
+	if rep.Manual > 0 {
+		fmt.Printf("✍️  Entered manually: %s, tracked live: %s\n", tracker.FormatDuration(rep.Manual), tracker.FormatDuration(rep.Total-rep.Manual))
+	}
+	printPaused(rep.Paused, rep.PausedByReason)
+}
+
+// manualNote marks how much of a duration was entered manually.
+func manualNote(manual time.Duration) string {
+	if manual <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("  (%s manual)", tracker.FormatDuration(manual))
+}
+
+// entryLabel names an entry, prefixed with its repository when the report
+// spans more than one repository.
+func entryLabel(e report.Entry, all []report.Entry) string {
+	for _, other := range all {
+		if other.RepoID != e.RepoID {
+			if e.RepoPath == "" {
+				return e.Name
+			}
+			return filepath.Base(e.RepoPath) + ":" + e.Name
+		}
+	}
+	return e.Name
+}
@@ -4,7 +4,6 @@ package main
 import (
 	"fmt"
 
-	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
 	"github.com/spf13/cobra"
 )
 
@@ -16,7 +15,7 @@ var resumeCmd = &cobra.Command{
 	Use:   "resume",
 	Short: "Resume tracking",
 	Run: func(cmd *cobra.Command, args []string) {
-		tr, branchName, err := tracker.Init()
+		tr, branchName, err := initTracker()
 		if err != nil {
 			fmt.Printf("❌ Failed to initialize tracker: %v\n", err)
 			return
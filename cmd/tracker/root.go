@@ -3,14 +3,32 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/impactj90/lofi-tracker/cmd/internal/config"
+	"github.com/impactj90/lofi-tracker/cmd/internal/daemon"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/ipc"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
 	"github.com/spf13/cobra"
 )
 
+var (
+	configPath string
+	dbPathFlag string
+	noDaemon   bool
+)
+
 var rootCmd = &cobra.Command{
-    Use:   "lofi-tracker",
-    Short: "Track your work time per Git branch",
-    Long:  `Lofi Tracker is a CLI tool to help you track working time on Git branches.`,
+	Use:   "lofi-tracker",
+	Short: "Track your work time per Git branch",
+	Long:  `Lofi Tracker is a CLI tool to help you track working time on Git branches.`,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file (default ~/.config/lofi-tracker/config.toml)")
+	rootCmd.PersistentFlags().StringVar(&dbPathFlag, "db", "", "Database file, overrides db_path from the config")
+	rootCmd.PersistentFlags().BoolVar(&noDaemon, "no-daemon", false, "Access the database directly even when the daemon is running")
 }
 
 func Execute() {
@@ -19,3 +37,102 @@ func Execute() {
 		os.Exit(1)
 	}
 }
+
+// resolveConfigPath returns the config file selected by --config or the default.
+func resolveConfigPath() (string, error) {
+	if configPath != "" {
+		return configPath, nil
+	}
+	return config.Path()
+}
+
+// repoRoot returns the top-level directory of the repository in the working
+// directory, or "" outside of a repository.
+func repoRoot() string {
+	repo, err := git.GetCurrentRepository()
+	if err != nil {
+		return ""
+	}
+	return repo.Root
+}
+
+// loadConfig loads the layered configuration, including the repository's
+// .lofi-tracker.toml, and applies the global flags. Commands apply their own
+// flags on top of the returned config.
+func loadConfig() (*config.Config, error) {
+	path, err := resolveConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.LoadWithRepo(path, repoRoot())
+	if err != nil {
+		return nil, err
+	}
+
+	if dbPathFlag != "" {
+		if err := cfg.Override("db_path", dbPathFlag); err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// initTracker loads the configuration and initializes a tracker for the
+// repository in the working directory. Commands go through the daemon while
+// it runs, so it sees every change, and open the database directly otherwise.
+func initTracker() (tracker.Tracker, string, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if tr, branchName, ok := dialDaemon(cfg); ok {
+		return tr, branchName, nil
+	}
+	return tracker.Init(cfg)
+}
+
+// dialDaemon connects to the running daemon, unless it works on another
+// database than cfg selects.
+func dialDaemon(cfg *config.Config) (tracker.Tracker, string, bool) {
+	if noDaemon {
+		return nil, "", false
+	}
+
+	socketPath, err := daemon.SocketPath()
+	if err != nil {
+		return nil, "", false
+	}
+	dbPath, err := cfg.ResolvedDBPath()
+	if err != nil {
+		return nil, "", false
+	}
+	dbPath, err = filepath.Abs(dbPath)
+	if err != nil {
+		return nil, "", false
+	}
+
+	branchName, err := git.GetCurrentBranchName()
+	if err != nil {
+		return nil, "", false
+	}
+	repo, err := git.GetCurrentRepository()
+	if err != nil {
+		return nil, "", false
+	}
+
+	client, err := ipc.Dial(socketPath, repo)
+	if err != nil {
+		return nil, "", false
+	}
+
+	info, err := client.Ping()
+	if err != nil || info.DBPath != dbPath {
+		client.Close()
+		return nil, "", false
+	}
+
+	return client, branchName, true
+}
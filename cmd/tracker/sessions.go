@@ -0,0 +1,65 @@
+// defines the sessions command
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/report"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var sessionsOn string
+
+func init() {
+	sessionsCmd.Flags().StringVar(&sessionsOn, "on", "today", "Day to list: today, yesterday, a weekday or YYYY-MM-DD")
+	rootCmd.AddCommand(sessionsCmd)
+}
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List the sessions of a day with their IDs",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("❌ Failed to load config: %v\n", err)
+			return
+		}
+
+		now := time.Now()
+		day, err := report.ParseDay(sessionsOn, now)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		database, err := openDB(cfg)
+		if err != nil {
+			fmt.Printf("❌ Failed to open database: %v\n", err)
+			return
+		}
+		defer database.Close()
+
+		r := report.DayRange(day)
+		sessions, err := database.ListSessions(db.SessionFilter{From: r.From, To: r.To})
+		if err != nil {
+			fmt.Printf("❌ Failed to load sessions: %v\n", err)
+			return
+		}
+
+		if len(sessions) == 0 {
+			fmt.Printf("💤 No sessions on %s\n", day.Format("Mon 2006-01-02"))
+			return
+		}
+
+		for _, s := range sessions {
+			note := ""
+			if s.Origin == db.OriginManual {
+				note = "  (manual)"
+			}
+			fmt.Printf("  %-60s %8s%s\n", sessionLine(s), tracker.FormatDuration(report.Worked(s, now)), note)
+		}
+	},
+}
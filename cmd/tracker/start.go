@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
 	"github.com/spf13/cobra"
 )
 
@@ -17,7 +16,7 @@ var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start tracking",
 	Run: func(cmd *cobra.Command, args []string) {
-		tr, branchName, err := tracker.Init()
+		tr, branchName, err := initTracker()
 		if err != nil {
 			fmt.Printf("❌ Failed to initialize tracker: %v\n", err)
 			return
@@ -25,7 +24,7 @@ var startCmd = &cobra.Command{
 
 		defer tr.Close()
 
-		tr.Start(branchName)
+		err = tr.Start(branchName)
 		if err != nil {
 			fmt.Printf("❌ Failed to start tracking: %v\n", err)
 			return
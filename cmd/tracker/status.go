@@ -4,6 +4,7 @@ package main
 import (
 	"fmt"
 
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
 	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
 	"github.com/spf13/cobra"
 )
@@ -16,7 +17,7 @@ var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show status",
 	Run: func(cmd *cobra.Command, args []string) {
-		tr, branchName, err := tracker.Init()
+		tr, _, err := initTracker()
 		if err != nil {
 			fmt.Printf("❌ Failed to initialize tracker: %v\n", err)
 			return
@@ -24,17 +25,31 @@ var statusCmd = &cobra.Command{
 
 		defer tr.Close()
 
+		// Asked first, so the status shows the kept time, and also without an
+		// active session: the idle time may be from a completed one
+		askAboutIdle(tr)
+
 		status, err := tr.Status()
 		if err != nil {
 			fmt.Printf("❌ Failed to get status: %v\n", err)
 			return
 		}
 
-		fmt.Printf("🕒 Total work time: %s on branch '%s'\n", tracker.FormatDuration(status.TotalDuration), branchName)
+		fmt.Printf("🕒 Total work time: %s on branch '%s'\n", tracker.FormatDuration(status.TotalDuration), status.Branch)
+		if status.Ticket != "" {
+			fmt.Printf("🎫 Ticket: %s\n", status.Ticket)
+		}
+		if status.RepoPath != "" {
+			fmt.Printf("📁 Repository: %s\n", status.RepoPath)
+			if repo, err := git.GetCurrentRepository(); err == nil && repo.ID != status.RepoID {
+				fmt.Printf("⚠️  The active session belongs to a different repository than '%s'\n", repo.Root)
+			}
+		}
+		printPauseBreakdown(status)
+		printKept(status)
 		if status.IsPaused {
-			fmt.Printf("⏸️  Session paused on branch '%s'\n", branchName)
+			fmt.Printf("⏸️  Session paused on branch '%s' (%s)\n", status.Branch, status.PauseReason)
 			return
 		}
-
 	},
 }
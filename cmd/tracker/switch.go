@@ -0,0 +1,90 @@
+// defines the switch command
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/git"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var switchCheckout bool
+
+func init() {
+	switchCmd.Flags().BoolVar(&switchCheckout, "checkout", false, "Run 'git checkout <branch>' before switching")
+	rootCmd.AddCommand(switchCmd)
+}
+
+var switchCmd = &cobra.Command{
+	Use:   "switch [branch]",
+	Short: "Move tracking to another branch without losing time in between",
+	Long: `Completes the active session and starts a new one at the same instant.
+Without a branch, tracking moves to the branch checked out in the working directory.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if switchCheckout && len(args) == 0 {
+			fmt.Println("❌ --checkout needs a branch")
+			return
+		}
+
+		tr, branchName, err := initTracker()
+		if err != nil {
+			fmt.Printf("❌ Failed to initialize tracker: %v\n", err)
+			return
+		}
+
+		defer tr.Close()
+
+		if len(args) == 1 {
+			branchName = args[0]
+		}
+
+		// Check for a session before touching the working tree, and put the
+		// previous branch back should the switch fail anyway
+		var previous string
+		if switchCheckout {
+			if _, err := tr.Status(); errors.Is(err, db.ErrNoActiveSession) {
+				fmt.Println("❌ No active session to switch, use 'lofi-tracker start'")
+				return
+			} else if err != nil {
+				fmt.Printf("❌ Failed to get status: %v\n", err)
+				return
+			}
+
+			if previous, err = git.GetCurrentBranchName(); err == nil && previous == "HEAD" {
+				previous, err = git.GetHeadCommit("")
+			}
+			if err != nil {
+				fmt.Printf("❌ Failed to get current branch: %v\n", err)
+				return
+			}
+			if err := git.Checkout("", branchName); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+		}
+
+		status, err := tr.Switch(branchName)
+		if err != nil {
+			if errors.Is(err, db.ErrNoActiveSession) {
+				fmt.Println("❌ No active session to switch, use 'lofi-tracker start'")
+			} else {
+				fmt.Printf("❌ Failed to switch tracking: %v\n", err)
+			}
+			if previous != "" {
+				if err := git.Checkout("", previous); err != nil {
+					fmt.Printf("⚠️  Failed to check out '%s' again: %v\n", previous, err)
+				} else {
+					fmt.Printf("↩️  Checked out '%s' again\n", previous)
+				}
+			}
+			return
+		}
+
+		fmt.Printf("✅ Completed session on branch '%s' (%s)\n", status.Branch, tracker.FormatDuration(status.TotalDuration))
+		fmt.Printf("🔀 Now tracking branch '%s'\n", branchName)
+	},
+}
@@ -0,0 +1,136 @@
+// defines the sync jira command
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/impactj90/lofi-tracker/cmd/internal/jira"
+	"github.com/impactj90/lofi-tracker/cmd/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var (
+	jiraDryRun    bool
+	jiraBaseURL   string
+	jiraEmail     string
+	jiraToken     string
+	jiraRoundTo   time.Duration
+	jiraRoundMode string
+	jiraMinimum   time.Duration
+)
+
+func init() {
+	syncJiraCmd.Flags().BoolVar(&jiraDryRun, "dry-run", false, "Show what would be logged without sending anything")
+	syncJiraCmd.Flags().StringVar(&jiraBaseURL, "url", "", "Jira base URL, e.g. https://yourteam.atlassian.net (default from jira.base_url)")
+	syncJiraCmd.Flags().StringVar(&jiraEmail, "email", "", "Jira account email, leave empty to use the token as a personal access token (default from jira.email)")
+	syncJiraCmd.Flags().StringVar(&jiraToken, "token", "", "Jira API token (default from jira.api_token)")
+	syncJiraCmd.Flags().DurationVar(&jiraRoundTo, "round", 0, "Round logged time to a multiple of this, e.g. 15m (default from jira.round_to)")
+	syncJiraCmd.Flags().StringVar(&jiraRoundMode, "round-mode", "", "Rounding direction: up, nearest or down (default from jira.round_mode)")
+	syncJiraCmd.Flags().DurationVar(&jiraMinimum, "min", 0, "Skip sessions with less worked time than this (default from jira.min_duration)")
+	syncCmd.AddCommand(syncJiraCmd)
+}
+
+var syncJiraCmd = &cobra.Command{
+	Use:   "jira",
+	Short: "Log completed sessions as Jira worklogs",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("❌ Failed to load config: %v\n", err)
+			return
+		}
+		if !cmd.Flags().Changed("url") {
+			jiraBaseURL = cfg.Jira.BaseURL
+		}
+		if !cmd.Flags().Changed("email") {
+			jiraEmail = cfg.Jira.Email
+		}
+		if !cmd.Flags().Changed("token") {
+			jiraToken = cfg.Jira.APIToken
+		}
+		if !cmd.Flags().Changed("round") {
+			jiraRoundTo = time.Duration(cfg.Jira.RoundTo)
+		}
+		if !cmd.Flags().Changed("round-mode") {
+			jiraRoundMode = cfg.Jira.RoundMode
+		}
+		if !cmd.Flags().Changed("min") {
+			jiraMinimum = time.Duration(cfg.Jira.MinDuration)
+		}
+
+		mode, err := jira.ParseRoundMode(jiraRoundMode)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		if !jiraDryRun && (jiraBaseURL == "" || jiraToken == "") {
+			fmt.Println("❌ Jira URL and token are required, set jira.base_url and jira.api_token or pass --url/--token")
+			return
+		}
+
+		tickets, err := cfg.TicketExtractor()
+		if err != nil {
+			fmt.Printf("❌ Failed to configure ticket extraction: %v\n", err)
+			return
+		}
+
+		database, err := openDB(cfg)
+		if err != nil {
+			fmt.Printf("❌ Failed to open database: %v\n", err)
+			return
+		}
+		defer database.Close()
+
+		syncer := &jira.Syncer{
+			Store:  database,
+			Client: jira.NewClient(jiraBaseURL, jiraEmail, jiraToken),
+			Rules:  jira.Rules{RoundTo: jiraRoundTo, Mode: mode, MinDuration: jiraMinimum},
+			IssueKey: func(s db.Session) string {
+				if s.Ticket != "" {
+					return s.Ticket
+				}
+				key, _ := tickets.Extract(s.Branch)
+				return key
+			},
+		}
+
+		results, err := syncer.Sync(context.Background(), jiraDryRun)
+		if err != nil {
+			fmt.Printf("❌ Failed to sync with Jira: %v\n", err)
+			return
+		}
+
+		if len(results) == 0 {
+			fmt.Println("✅ Nothing to sync")
+			return
+		}
+
+		var synced, failed int
+		for _, r := range results {
+			started := r.Session.StartTime.Local().Format("2006-01-02 15:04")
+			switch {
+			case r.Skipped != "":
+				fmt.Printf("⏭️  #%d %s %s: skipped, %s\n", r.Session.ID, started, r.Session.Branch, r.Skipped)
+			case r.Err != nil:
+				failed++
+				fmt.Printf("❌ #%d %s %s: %v\n", r.Session.ID, started, r.IssueKey, r.Err)
+			case jiraDryRun:
+				fmt.Printf("📝 #%d %s %s: would log %s\n", r.Session.ID, started, r.IssueKey, tracker.FormatDuration(r.TimeSpent))
+			case r.Recovered:
+				synced++
+				fmt.Printf("🔁 #%d %s %s: already logged as worklog %s\n", r.Session.ID, started, r.IssueKey, r.WorklogID)
+			default:
+				synced++
+				fmt.Printf("✅ #%d %s %s: logged %s as worklog %s\n", r.Session.ID, started, r.IssueKey, tracker.FormatDuration(r.TimeSpent), r.WorklogID)
+			}
+		}
+
+		if !jiraDryRun {
+			fmt.Printf("🔄 Synced %d session(s), %d failed\n", synced, failed)
+		}
+	},
+}
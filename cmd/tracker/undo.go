@@ -0,0 +1,95 @@
+// defines the undo command
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/impactj90/lofi-tracker/cmd/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	undoSteps int
+	undoList  bool
+)
+
+func init() {
+	undoCmd.Flags().IntVarP(&undoSteps, "steps", "n", 1, "Number of actions to undo")
+	undoCmd.Flags().BoolVar(&undoList, "list", false, "Show the actions that can be undone, newest first")
+	rootCmd.AddCommand(undoCmd)
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the last start, pause, resume, complete or switch",
+	Long: `Reverts the most recent tracker actions, newest first. Undoing complete
+reopens the session, so the time since then counts as worked.
+Sessions changed with edit or delete can no longer be undone.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if undoList {
+			listOperations()
+			return
+		}
+
+		tr, _, err := initTracker()
+		if err != nil {
+			fmt.Printf("❌ Failed to initialize tracker: %v\n", err)
+			return
+		}
+
+		defer tr.Close()
+
+		for i := 0; i < undoSteps; i++ {
+			op, err := tr.Undo()
+			if errors.Is(err, db.ErrNothingToUndo) && i > 0 {
+				fmt.Println("💤 Nothing more to undo")
+				return
+			}
+			if err != nil {
+				fmt.Printf("❌ Failed to undo: %v\n", err)
+				return
+			}
+			fmt.Printf("↩️  Undid %s\n", operationLine(op))
+		}
+	},
+}
+
+func listOperations() {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("❌ Failed to load config: %v\n", err)
+		return
+	}
+
+	database, err := openDB(cfg)
+	if err != nil {
+		fmt.Printf("❌ Failed to open database: %v\n", err)
+		return
+	}
+	defer database.Close()
+
+	ops, err := database.ListOperations(20)
+	if err != nil {
+		fmt.Printf("❌ Failed to load actions: %v\n", err)
+		return
+	}
+
+	if len(ops) == 0 {
+		fmt.Println("💤 Nothing to undo")
+		return
+	}
+
+	for _, op := range ops {
+		fmt.Printf("  %s\n", operationLine(op))
+	}
+}
+
+// operationLine describes a journal entry on one line.
+func operationLine(op db.Operation) string {
+	line := fmt.Sprintf("%s #%d %s at %s", op.Kind, op.SessionID, op.Branch, op.At.Local().Format("Mon 2006-01-02 15:04"))
+	if op.Kind == db.OpSwitch {
+		line += fmt.Sprintf(", started #%d", op.NextSessionID)
+	}
+	return line
+}